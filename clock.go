@@ -0,0 +1,371 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/alejoar/factorialsucks/factorial"
+	"github.com/briandowns/spinner"
+	"github.com/urfave/cli/v2"
+)
+
+func clockCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "clock",
+		Usage:     "clock in every working day of the month or range",
+		UsageText: "factorialsucks clock [options]",
+		Description: "Adds shifts for the laborable days of the month, or of the --from/--to range,\n" +
+			"following the schedule rules and skipping leaves and holidays. Days whose shifts overlap\n" +
+			"the ones already recorded are skipped, or filled or replaced with --on-overlap.",
+		Flags:  clockFlags(false),
+		Action: clock,
+	}
+}
+
+func clockFlags(hidden bool) []cli.Flag {
+	return append(rangeFlags(hidden),
+		&cli.StringFlag{
+			Name:    "clock-in",
+			Aliases: []string{"ci"},
+			Usage:   "clock-in time `HH:MM`",
+			Value:   "09:00",
+			Hidden:  hidden,
+		},
+		&cli.StringFlag{
+			Name:    "clock-out",
+			Aliases: []string{"co"},
+			Usage:   "clock-in time `HH:MM`",
+			Value:   "18:00",
+			Hidden:  hidden,
+		},
+		&cli.BoolFlag{
+			Name:    "today",
+			Aliases: []string{"t"},
+			Usage:   "clock in for today only",
+			Value:   false,
+			Hidden:  hidden,
+		},
+		&cli.BoolFlag{
+			Name:    "until-today",
+			Aliases: []string{"ut"},
+			Usage:   "clock in only until today",
+			Value:   false,
+			Hidden:  hidden,
+		},
+		&cli.BoolFlag{
+			Name:    "dry-run",
+			Aliases: []string{"dr"},
+			Usage:   "do a dry run without actually clocking in",
+			Hidden:  hidden,
+		},
+		&cli.StringFlag{
+			Name:        "schedule",
+			Aliases:     []string{"s"},
+			Usage:       "schedule rules `FILE` (YAML)",
+			DefaultText: "built-in schedule",
+			EnvVars:     []string{"SCHEDULE"},
+			Hidden:      hidden,
+		},
+		&cli.StringFlag{
+			Name:   "on-failure",
+			Usage:  "what to do when a day with breaks fails half way: rollback its segments or resume from the last step recorded (`MODE`)",
+			Value:  string(factorial.RecoveryRollback),
+			Hidden: hidden,
+		},
+		overlapFlag(hidden),
+		replaceBackupFlag(hidden),
+		&cli.BoolFlag{
+			Name:   "fill-gaps",
+			Usage:  "complete days partially recorded: add the expected time not tracked yet around the shifts recorded, same as --on-overlap fill-gaps",
+			Hidden: hidden,
+		},
+		&cli.IntFlag{
+			Name:    "workers",
+			Aliases: []string{"w"},
+			Usage:   "record up to `N` days at once within the same --rate, the clock in and break requests of days with breaks still go one day at a time",
+			Value:   1,
+			EnvVars: []string{"WORKERS"},
+			Hidden:  hidden,
+		},
+		jitterFlag(hidden),
+		seedFlag(hidden),
+		locationFlag(hidden),
+	)
+}
+
+func overlapFlag(hidden bool) cli.Flag {
+	return &cli.StringFlag{
+		Name:   "on-overlap",
+		Usage:  "what to do when a shift overlaps the ones already recorded: skip the day, fill-gaps to complete the day around them (clock only), or replace the overlapping shifts (`POLICY`)",
+		Value:  string(factorial.OverlapSkip),
+		Hidden: hidden,
+	}
+}
+
+func replaceBackupFlag(hidden bool) cli.Flag {
+	return &cli.StringFlag{
+		Name:        "backup",
+		Usage:       "write the shifts deleted by --on-overlap replace to `FILE`",
+		DefaultText: "factorialsucks/backups/replace-<time>.json in your config directory",
+		Hidden:      hidden,
+	}
+}
+
+func locationFlag(hidden bool) cli.Flag {
+	return &cli.StringFlag{
+		Name:        "location",
+		Aliases:     []string{"l"},
+		Usage:       "work every shift from `LOCATION`: office, work_from_home or business_trip",
+		DefaultText: "schedule location rules, or work_from_home",
+		Hidden:      hidden,
+	}
+}
+
+// selectedLocation returns the validated --location, empty when not set
+func selectedLocation(c *cli.Context) (string, error) {
+	if c.String("location") == "" {
+		return "", nil
+	}
+	return factorial.ParseLocation(c.String("location"))
+}
+
+func jitterFlag(hidden bool) cli.Flag {
+	return &cli.IntFlag{
+		Name:    "jitter",
+		Usage:   "move the clock in and breaks of each day by up to `MINUTES`, keeping the time worked",
+		EnvVars: []string{"JITTER"},
+		Hidden:  hidden,
+	}
+}
+
+func seedFlag(hidden bool) cli.Flag {
+	return &cli.Int64Flag{
+		Name:        "seed",
+		Usage:       "random `SEED` for --jitter, the same seed gives the same times",
+		DefaultText: "random",
+		Hidden:      hidden,
+	}
+}
+
+// jitterSeed returns --seed, or a random seed that is logged so the run can
+// be repeated
+func jitterSeed(c *cli.Context) int64 {
+	if c.IsSet("seed") {
+		return c.Int64("seed")
+	}
+	seed := time.Now().UnixNano()
+	if c.Int("jitter") > 0 {
+		log.Printf("Jitter seed: %d (use --seed to repeat the times)", seed)
+	}
+	return seed
+}
+
+func clock(c *cli.Context) error {
+	opts := factorial.ClockInOptions{
+		ClockIn:    c.String("clock-in"),
+		ClockOut:   c.String("clock-out"),
+		TodayOnly:  c.Bool("today"),
+		UntilToday: c.Bool("until-today"),
+		DryRun:     c.Bool("dry-run"),
+		Now:        today,
+		Jitter:     c.Int("jitter"),
+		Seed:       jitterSeed(c),
+		Workers:    c.Int("workers"),
+		Backup:     c.String("backup"),
+	}
+	if opts.Workers < 1 {
+		return fmt.Errorf("--workers must be at least 1, got %d", opts.Workers)
+	}
+	recovery, err := factorial.ParseRecovery(c.String("on-failure"))
+	if err != nil {
+		return err
+	}
+	opts.Recovery = recovery
+	if opts.Overlap, err = factorial.ParseOverlapPolicy(c.String("on-overlap")); err != nil {
+		return err
+	}
+	if c.Bool("fill-gaps") {
+		if c.IsSet("on-overlap") && opts.Overlap != factorial.OverlapFillGaps {
+			return fmt.Errorf("--fill-gaps can't be used with --on-overlap %s", opts.Overlap)
+		}
+		opts.Overlap = factorial.OverlapFillGaps
+	}
+	if opts.Location, err = selectedLocation(c); err != nil {
+		return err
+	}
+	if opts.Schedule, err = loadSchedule(c); err != nil {
+		return err
+	}
+
+	spin := newSpinner()
+	spin.Start()
+	defer spin.Stop()
+	client, months, r, err := loadMonths(c, spin)
+	if err != nil {
+		return err
+	}
+
+	spin.Suffix = " Clocking in..."
+	summary := clockSummary{Type: "summary", From: r.From.Format("2006-01-02"), To: r.To.Format("2006-01-02"), DryRun: opts.DryRun}
+	if opts.Jitter > 0 {
+		summary.Seed = &opts.Seed
+	}
+	err = client.ClockInMonths(c.Context, months, opts, reportDay(spin, &summary))
+	spin.Stop()
+	return finishRun(summary, err)
+}
+
+// reportDay returns a callback printing each day of a run and counting it in
+// summary
+func reportDay(spin *spinner.Spinner, summary *clockSummary) func(factorial.DayResult) {
+	return func(r factorial.DayResult) {
+		spin.Stop()
+		if r.Backup != "" && summary.Backup == "" {
+			summary.Backup = r.Backup
+			fmt.Fprintf(out.prompts(), "Replaced shifts written to %s\n", r.Backup)
+		}
+		record := newClockRecord(r)
+		switch record.Action {
+		case "created":
+			summary.Created++
+		case "planned":
+			summary.Planned++
+		case "skipped":
+			summary.Skipped++
+		case "failed":
+			summary.Failed++
+		}
+		if out.structured() {
+			out.record(record)
+		} else {
+			printDay(r)
+		}
+		spin.Start()
+	}
+}
+
+// finishRun prints the summary of a run in the JSON formats, or done! when
+// it succeeds otherwise
+func finishRun(summary clockSummary, err error) error {
+	if out.structured() {
+		summary.Error = errorString(err)
+		summary.ExitCode = exitCode(err)
+		if err := out.summary(summary); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !out.structured() {
+		fmt.Println("done!")
+	}
+	return nil
+}
+
+// clockRecord is the JSON output of a clocked in day. Action is created,
+// planned (dry run), skipped or failed.
+type clockRecord struct {
+	Type     string              `json:"type"`
+	Date     string              `json:"date"`
+	Action   string              `json:"action"`
+	Reason   string              `json:"reason,omitempty"`
+	Segments []factorial.Segment `json:"segments,omitempty"`
+	Recovery string              `json:"recovery,omitempty"`
+	Overlap  string              `json:"overlap,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+func newClockRecord(r factorial.DayResult) clockRecord {
+	record := clockRecord{
+		Type:     "day",
+		Date:     r.Date.Format("2006-01-02"),
+		Recovery: r.Recovery,
+		Overlap:  r.Overlap,
+		Error:    errorString(r.Err),
+	}
+	switch {
+	case r.Skipped:
+		record.Action = "skipped"
+		record.Reason = r.Reason
+		return record
+	case r.Err != nil:
+		record.Action = "failed"
+		return record
+	case r.DryRun:
+		record.Action = "planned"
+	default:
+		record.Action = "created"
+	}
+	record.Segments = r.Segments()
+	return record
+}
+
+// clockSummary ends the JSON output of a clock in or import run. File is the
+// file imported, Seed the one used for the jitter and Backup the file the
+// replaced shifts were written to.
+type clockSummary struct {
+	Type     string `json:"type"`
+	File     string `json:"file,omitempty"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	DryRun   bool   `json:"dry_run"`
+	Seed     *int64 `json:"seed,omitempty"`
+	Backup   string `json:"backup,omitempty"`
+	Created  int    `json:"created"`
+	Planned  int    `json:"planned"`
+	Skipped  int    `json:"skipped"`
+	Failed   int    `json:"failed"`
+	Error    string `json:"error,omitempty"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// loadSchedule reads the --schedule file, the built-in schedule is used when
+// it's not set
+func loadSchedule(c *cli.Context) (*factorial.Schedule, error) {
+	path := c.String("schedule")
+	if path == "" {
+		return factorial.DefaultSchedule(), nil
+	}
+	return factorial.LoadSchedule(path)
+}
+
+func printDay(r factorial.DayResult) {
+	message := fmt.Sprintf("%s... ", r.Date.Format("02 Jan"))
+	switch {
+	case r.Skipped:
+		message = fmt.Sprintf("%s ❌ %s", message, r.Reason)
+	case r.Err != nil:
+		message = fmt.Sprintf("%s ❌ Error when attempting to clock in: %v", message, r.Err)
+		if r.Recovery != "" {
+			message = fmt.Sprintf("%s (%s)", message, r.Recovery)
+		}
+	case r.Recovery != "":
+		message = fmt.Sprintf("%s ✅ %s - %s%s (%s)", message, r.Shift.ClockIn, r.Shift.ClockOut, describeLocation(r), r.Recovery)
+	case r.DryRun:
+		message = fmt.Sprintf("%s ✅ %s - %s%s (dry run)", message, r.Shift.ClockIn, r.Shift.ClockOut, describeLocation(r))
+	default:
+		message = fmt.Sprintf("%s ✅ %s - %s%s", message, r.Shift.ClockIn, r.Shift.ClockOut, describeLocation(r))
+	}
+	if r.Overlap != "" && !r.Skipped {
+		message = fmt.Sprintf("%s (%s)", message, r.Overlap)
+	}
+	fmt.Println(message)
+}
+
+// describeLocation lists where the day is worked from, or nothing when it's
+// all from home
+func describeLocation(r factorial.DayResult) string {
+	var locations []string
+	for _, s := range r.Segments() {
+		if len(locations) == 0 || locations[len(locations)-1] != s.Location {
+			locations = append(locations, s.Location)
+		}
+	}
+	if len(locations) == 1 && locations[0] == factorial.LocationRemote {
+		return ""
+	}
+	return " @ " + strings.Join(locations, ", ")
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alejoar/factorialsucks/factorial"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+func configCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "config",
+		Usage:     "show the configuration in use",
+		UsageText: "factorialsucks config [options]",
+		Description: "Prints the account, session and request settings and the schedule rules in use.\n" +
+			"The rules are printed as YAML, so they can be used as a starting point for --schedule.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "schedule",
+				Aliases:     []string{"s"},
+				Usage:       "schedule rules `FILE` (YAML)",
+				DefaultText: "built-in schedule",
+				EnvVars:     []string{"SCHEDULE"},
+			},
+		},
+		Action: config,
+	}
+}
+
+func config(c *cli.Context) error {
+	schedule, err := loadSchedule(c)
+	if err != nil {
+		return err
+	}
+
+	summary := configSummary{
+		Type:         "summary",
+		Email:        c.String("email"),
+		Api:          c.String("base-url"),
+		MaxRetries:   c.Int("max-retries"),
+		Rate:         c.Float64("rate"),
+		TimeZone:     timeZone.String(),
+		ScheduleFile: c.String("schedule"),
+		Schedule:     schedule,
+	}
+	path, err := sessionPath(c)
+	var session *factorial.Session
+	if err == nil && path != "" {
+		summary.Session = path
+		if saved, err := factorial.LoadSession(path); err == nil {
+			session = saved
+			summary.SessionSavedAt = &session.SavedAt
+		}
+	}
+	if out.structured() {
+		return out.summary(summary)
+	}
+
+	fmt.Printf("# email: %s\n", summary.Email)
+	fmt.Printf("# api: %s\n", summary.Api)
+	switch {
+	case err != nil:
+		fmt.Printf("# session: not saved (%v)\n", err)
+	case path == "":
+		fmt.Println("# session: not saved (--no-session)")
+	case session != nil:
+		fmt.Printf("# session: %s (saved %s)\n", path, session.SavedAt.Format("2006-01-02 15:04"))
+	default:
+		fmt.Printf("# session: %s (none yet)\n", path)
+	}
+	fmt.Printf("# retries: %d, rate: %g requests/s\n", summary.MaxRetries, summary.Rate)
+	fmt.Printf("# time zone: %s\n", summary.TimeZone)
+	if summary.ScheduleFile != "" {
+		fmt.Printf("# schedule: %s\n", summary.ScheduleFile)
+	} else {
+		fmt.Println("# schedule: built-in")
+	}
+
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	defer enc.Close()
+	return enc.Encode(schedule)
+}
+
+// configSummary is the JSON output of config. Session is empty when sessions
+// are disabled, and ScheduleFile when the built-in schedule is used.
+type configSummary struct {
+	Type           string              `json:"type"`
+	Email          string              `json:"email"`
+	Api            string              `json:"api"`
+	Session        string              `json:"session"`
+	SessionSavedAt *time.Time          `json:"session_saved_at,omitempty"`
+	MaxRetries     int                 `json:"max_retries"`
+	Rate           float64             `json:"rate"`
+	TimeZone       string              `json:"time_zone"`
+	ScheduleFile   string              `json:"schedule_file"`
+	Schedule       *factorial.Schedule `json:"schedule"`
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/alejoar/factorialsucks/factorial"
+	"github.com/urfave/cli/v2"
+)
+
+func daemonCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "daemon",
+		Usage:     "clock in live every working day, at the times of the schedule",
+		UsageText: "factorialsucks daemon [options]",
+		Description: "Keeps running and calls Factorial's clock in, break and clock out endpoints at the\n" +
+			"times of the schedule rules, so shifts are recorded as they happen instead of back-dated.\n" +
+			"Leaves and holidays are skipped. Steps already recorded are not sent again, so the daemon\n" +
+			"can be restarted at any time. Every action is logged. Stop it with Ctrl+C or SIGTERM.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "clock-in",
+				Aliases: []string{"ci"},
+				Usage:   "clock-in time `HH:MM`",
+				Value:   "09:00",
+			},
+			&cli.StringFlag{
+				Name:    "clock-out",
+				Aliases: []string{"co"},
+				Usage:   "clock-out time `HH:MM`",
+				Value:   "18:00",
+			},
+			&cli.StringFlag{
+				Name:        "schedule",
+				Aliases:     []string{"s"},
+				Usage:       "schedule rules `FILE` (YAML)",
+				DefaultText: "built-in schedule",
+				EnvVars:     []string{"SCHEDULE"},
+			},
+			jitterFlag(false),
+			&cli.Int64Flag{
+				Name:        "seed",
+				Usage:       "random `SEED` for --jitter, keep it when restarting so the steps recorded are recognized",
+				DefaultText: "derived from your employee id",
+			},
+			locationFlag(false),
+			&cli.DurationFlag{
+				Name:  "max-delay",
+				Usage: "skip a day that can't be clocked in within `DURATION` of its clock in time",
+				Value: 15 * time.Minute,
+			},
+		},
+		Action: daemon,
+	}
+}
+
+func daemon(c *cli.Context) error {
+	if out.format == outputJSON {
+		return errors.New("the daemon runs until stopped, use --output ndjson")
+	}
+	opts := factorial.DaemonOptions{
+		ClockIn:  c.String("clock-in"),
+		ClockOut: c.String("clock-out"),
+		MaxDelay: c.Duration("max-delay"),
+		Jitter:   c.Int("jitter"),
+		Seed:     c.Int64("seed"),
+	}
+	var err error
+	if opts.Location, err = selectedLocation(c); err != nil {
+		return err
+	}
+	if opts.Schedule, err = loadSchedule(c); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(c.Context)
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	spin := newSpinner()
+	client, err := newClient(c, spin)
+	if err != nil {
+		return err
+	}
+	for {
+		err = client.Daemon(ctx, opts, logDaemonEvent)
+		var authErr *factorial.AuthError
+		if ctx.Err() != nil {
+			logDaemonEvent(factorial.DaemonEvent{Time: time.Now(), Action: "stopped"})
+			return nil
+		}
+		if !errors.As(err, &authErr) {
+			return err
+		}
+		// Sessions expire while the daemon runs, log in again and go on
+		logDaemonEvent(factorial.DaemonEvent{Time: time.Now(), Action: "login", Err: err})
+		if err := login(c, client, c.String("email"), spin); err != nil {
+			return err
+		}
+	}
+}
+
+// daemonRecord is the JSON output of a daemon action
+type daemonRecord struct {
+	Type     string `json:"type"`
+	Time     string `json:"time"`
+	Date     string `json:"date,omitempty"`
+	Action   string `json:"action"`
+	Endpoint string `json:"endpoint,omitempty"`
+	At       string `json:"at,omitempty"`
+	Message  string `json:"message,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// logDaemonEvent prints a daemon action as a timestamped line, or as a record
+// in ndjson
+func logDaemonEvent(e factorial.DaemonEvent) {
+	record := daemonRecord{
+		Type:     "event",
+		Time:     e.Time.Format(time.RFC3339),
+		Action:   e.Action,
+		Endpoint: e.Endpoint,
+		At:       e.At,
+		Message:  e.Message,
+		Error:    errorString(e.Err),
+	}
+	if !e.Date.IsZero() {
+		record.Date = e.Date.Format("2006-01-02")
+	}
+	if out.structured() {
+		out.record(record)
+		return
+	}
+
+	parts := []string{e.Time.Format("2006-01-02 15:04:05"), e.Action}
+	if record.Date != "" {
+		parts = append(parts, record.Date)
+	}
+	if e.Endpoint != "" {
+		parts = append(parts, fmt.Sprintf("%s at %s", e.Endpoint, e.At))
+	}
+	if e.Message != "" {
+		parts = append(parts, e.Message)
+	}
+	if e.Err != nil {
+		parts = append(parts, fmt.Sprintf("(%v)", e.Err))
+	}
+	fmt.Println(strings.Join(parts, " "))
+}
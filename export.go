@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alejoar/factorialsucks/factorial"
+	"github.com/urfave/cli/v2"
+)
+
+// Export formats
+const (
+	exportCSV  = "csv"
+	exportICS  = "ics"
+	exportJSON = "json"
+)
+
+func exportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "export",
+		Usage:     "export the shifts of the month or range",
+		UsageText: "factorialsucks export [options]",
+		Description: "Writes the shifts recorded in the month, or in the --from/--to range, as CSV (date,\n" +
+			"clock in, clock out, minutes, location type and source), as iCalendar events or as JSON\n" +
+			"that restore can read back.",
+		Flags: append(rangeFlags(false),
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "export format: csv, ics or json (`FORMAT`)",
+				DefaultText: "from the --file extension, csv otherwise",
+			},
+			&cli.StringFlag{
+				Name:        "file",
+				Aliases:     []string{"f"},
+				Usage:       "write to `FILE`",
+				DefaultText: "stdout",
+			},
+		),
+		Action: export,
+	}
+}
+
+func export(c *cli.Context) error {
+	path := c.String("file")
+	format := c.String("format")
+	if format == "" {
+		format = exportCSV
+		if ext := strings.TrimPrefix(filepath.Ext(path), "."); ext == exportICS || ext == exportJSON {
+			format = ext
+		}
+	}
+	if format != exportCSV && format != exportICS && format != exportJSON {
+		return fmt.Errorf("invalid export format %q, use %s, %s or %s", format, exportCSV, exportICS, exportJSON)
+	}
+	if path == "" && out.structured() {
+		return errors.New("export writes to stdout, use --file with --output " + out.format)
+	}
+
+	spin := newSpinner()
+	if path == "" {
+		// The export is the output, keep it clean
+		spin.Writer = ioutil.Discard
+	}
+	spin.Start()
+	defer spin.Stop()
+	client, months, r, err := loadMonths(c, spin)
+	if err != nil {
+		return err
+	}
+	spin.Stop()
+
+	backup := client.NewBackup()
+	for _, m := range months {
+		backup.Add(m, m.FindShifts(factorial.ShiftFilter{}))
+	}
+
+	if path == "" {
+		return writeExport(os.Stdout, format, backup)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeExport(f, format, backup); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if out.structured() {
+		return out.summary(exportSummary{
+			Type:   "summary",
+			From:   r.From.Format("2006-01-02"),
+			To:     r.To.Format("2006-01-02"),
+			File:   path,
+			Format: format,
+			Shifts: len(backup.Shifts),
+		})
+	}
+	fmt.Printf("Exported %d shift(s) to %s\n", len(backup.Shifts), path)
+	return nil
+}
+
+func writeExport(w io.Writer, format string, backup *factorial.Backup) error {
+	switch format {
+	case exportICS:
+		return factorial.WriteICS(w, backup.Shifts, backup.SavedAt)
+	case exportJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(backup)
+	}
+	return factorial.WriteCSV(w, backup.Shifts)
+}
+
+// exportSummary is the JSON output of an export to a file
+type exportSummary struct {
+	Type   string `json:"type"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	File   string `json:"file"`
+	Format string `json:"format"`
+	Shifts int    `json:"shifts"`
+}
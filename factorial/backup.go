@@ -0,0 +1,90 @@
+package factorial
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Backup is a copy of shifts written before deleting them, so they can be
+// created again
+type Backup struct {
+	BaseUrl    string        `json:"base_url"`
+	EmployeeId int           `json:"employee_id"`
+	SavedAt    time.Time     `json:"saved_at"`
+	Shifts     []BackupShift `json:"shifts"`
+}
+
+// BackupShift is a shift of a backup together with its date (YYYY-MM-DD)
+type BackupShift struct {
+	Date string `json:"date"`
+	Shift
+}
+
+// NewBackup returns an empty backup of the client's account
+func (c *Client) NewBackup() *Backup {
+	return &Backup{BaseUrl: c.baseUrl(), SavedAt: time.Now()}
+}
+
+// Add copies shifts of a month to the backup, keeping them in date and
+// clock in order
+func (b *Backup) Add(m *Month, shifts []Shift) {
+	b.EmployeeId = m.EmployeeId
+	for _, s := range shifts {
+		b.Shifts = append(b.Shifts, BackupShift{Date: m.Date(s.Day).Format("2006-01-02"), Shift: s})
+	}
+	sortShifts(b.Shifts)
+}
+
+// sortShifts orders shifts by date and clock in
+func sortShifts(shifts []BackupShift) {
+	sort.SliceStable(shifts, func(i, j int) bool {
+		if shifts[i].Date != shifts[j].Date {
+			return shifts[i].Date < shifts[j].Date
+		}
+		return shifts[i].ClockIn < shifts[j].ClockIn
+	})
+}
+
+// DefaultBackupPath returns where a backup taken at t by a command, reset or
+// replace, is written by default, inside the user's config directory
+func DefaultBackupPath(command string, t time.Time) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s.json", command, t.Format("20060102-150405"))
+	return filepath.Join(dir, "factorialsucks", "backups", name), nil
+}
+
+// LoadBackup reads a backup written with Save, or a JSON array of shifts
+// with their dates
+func LoadBackup(path string) (*Backup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Backup
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(data, &b.Shifts)
+	} else {
+		err = json.Unmarshal(data, &b)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &b, nil
+}
+
+// Save writes the backup to path, readable by the current user only
+func (b *Backup) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writePrivateFile(path, data)
+}
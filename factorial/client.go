@@ -2,431 +2,295 @@ package factorial
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/briandowns/spinner"
 	"golang.org/x/net/publicsuffix"
 )
 
 const BaseUrl = "https://api.factorialhr.com"
 
-// Shift durations in minutes
-const (
-	RegularShiftMinutes = 495 // 8:15 hours
-	FridayShiftMinutes  = 420 // 7:00 hours
-)
-
-// NewFactorialClient creates a new client and initializes it with the required data
-func NewFactorialClient(email, password string, year, month int, in, out string, todayOnly, untilToday bool) *factorialClient {
-	spin := spinner.New(spinner.CharSets[14], 60*time.Millisecond)
-	spin.Start()
-
-	c := &factorialClient{
-		year:       year,
-		month:      month,
-		clockIn:    in,
-		clockOut:   out,
-		todayOnly:  todayOnly,
-		untilToday: untilToday,
-	}
+// Client talks to the FactorialHR API. The session is kept in the cookie jar
+// of the embedded http.Client, so Login must be called before anything else.
+type Client struct {
+	http.Client
+	// BaseUrl is the root of the API, the BaseUrl constant when empty
+	BaseUrl string
+	// TimeZone is the company's, where the days begin and end and the shift
+	// times are in. Defaults to time.Local.
+	TimeZone *time.Location
+	// clocking holds a lock per employee, see clockingLock
+	clockingMu sync.Mutex
+	clocking   map[int]*sync.Mutex
+}
 
-	// Setup HTTP client with cookie jar
+// NewClient creates a client with an empty cookie jar, retrying failed
+// requests up to DefaultMaxRetries times. Replace its Transport with another
+// RetryTransport to change the retries or limit the request rate.
+func NewClient() *Client {
 	options := cookiejar.Options{
 		PublicSuffixList: publicsuffix.List,
 	}
 	jar, _ := cookiejar.New(&options)
-	c.Client = http.Client{Jar: jar}
-
-	// Initialize client data
-	spin.Suffix = " Logging in..."
-	handleError(spin, c.login(email, password))
-	spin.Suffix = " Getting periods data..."
-	handleError(spin, c.setPeriodId())
-	spin.Suffix = " Getting calendar data..."
-	handleError(spin, c.setCalendar())
-	spin.Suffix = " Getting shifts data..."
-	handleError(spin, c.setShifts())
-
-	spin.Stop()
-	return c
-}
-
-// ClockIn adds shifts for the specified period
-func (c *factorialClient) ClockIn(dryRun bool) {
-	spin := spinner.New(spinner.CharSets[14], 60*time.Millisecond)
-	now := time.Now()
-
-	for _, day := range c.calendar {
-		spin.Restart()
-		spin.Reverse()
-
-		date := time.Date(c.year, time.Month(c.month), day.Day, 0, 0, 0, 0, time.UTC)
-		message := fmt.Sprintf("%s... ", date.Format("02 Jan"))
-		spin.Prefix = message + " "
-
-		// Skip if conditions are not met
-		if skip, reason := c.shouldSkipDay(day, date, now); skip {
-			message = fmt.Sprintf("%s ❌ %s\n", message, reason)
-			spin.Stop()
-			fmt.Print(message)
-			continue
-		}
-
-		// Create and add shift
-		shift := c.createShift(day)
-		if !dryRun {
-			ok := c.addShift(shift)
-			if ok {
-				message = fmt.Sprintf("%s ✅ %s - %s\n", message, shift.ClockIn, shift.ClockOut)
-			} else {
-				message = fmt.Sprintf("%s ❌ Error when attempting to clock in\n", message)
-			}
-		} else {
-			message = fmt.Sprintf("%s ✅ %s - %s (dry run)\n", message, shift.ClockIn, shift.ClockOut)
-		}
-
-		spin.Stop()
-		fmt.Print(message)
+	return &Client{
+		Client: http.Client{
+			Jar:       jar,
+			Transport: NewRetryTransport(DefaultMaxRetries, 0),
+		},
+		BaseUrl: BaseUrl,
 	}
-	fmt.Println("done!")
 }
 
-// shouldSkipDay determines if a day should be skipped and why
-func (c *factorialClient) shouldSkipDay(day calendarDay, date time.Time, now time.Time) (bool, string) {
-	// Check for existing shifts
-	if clockedIn, times := c.clockedIn(day.Day, newShift{ClockIn: c.clockIn, ClockOut: c.clockOut}); clockedIn {
-		return true, fmt.Sprintf("Period overlap: %s", times)
+// Login signs in with email and password
+func (c *Client) Login(ctx context.Context, email, password string) error {
+	resp, err := c.request(ctx, "GET", "/users/sign_in", "", nil)
+	if err != nil {
+		return err
 	}
-
-	// Check for leaves
-	if day.IsLeave {
-		return true, day.LeaveName
+	page, err := readBody(resp)
+	if err != nil {
+		return err
 	}
-
-	// Check for non-laborable days
-	if !day.IsLaborable {
-		return true, date.Format("Monday")
+	csrfToken := between(page, "<meta name=\"csrf-token\" content=\"", "\" />")
+	if csrfToken == "" {
+		return &AuthError{Message: "csrf token not found in the sign in page"}
 	}
 
-	// Check for today-only flag
-	if c.todayOnly && day.Day != now.Day() {
-		return true, "Skipping: --today"
+	body := url.Values{
+		"authenticity_token": {csrfToken},
+		"return_host":        {"factorialhr.es"},
+		"user[email]":        {email},
+		"user[password]":     {password},
+		"user[remember_me]":  {"0"},
+		"commit":             {"Sign in"},
 	}
-
-	// Check for until-today flag
-	if c.untilToday && day.Day > now.Day() {
-		return true, "Skipping: --until-today"
+	resp, err = c.request(ctx, "POST", "/users/sign_in", "application/x-www-form-urlencoded", strings.NewReader(body.Encode()))
+	if err != nil {
+		return err
 	}
-
-	return false, ""
-}
-
-// createShift creates a shift for the given day with appropriate times
-func (c *factorialClient) createShift(day calendarDay) newShift {
-	shift := newShift{
-		ClockIn:                          c.clockIn,
-		ClockOut:                         c.clockOut,
-		Day:                              day.Day,
-		EmployeeId:                       c.employeeId,
-		Workable:                         true,
-		LocationType:                     "work_from_home",
-		Source:                           "desktop",
-		TimeSettingsBreakConfigurationId: nil,
-		Minutes:                          nil,
+	page, err = readBody(resp)
+	if err != nil {
+		return err
 	}
-
-	// Parse the date to check schedule
-	date, _ := time.Parse("2006-01-02", day.Date)
-	month := date.Month()
-	dayOfMonth := date.Day()
-
-	// Check if date is in summer schedule (July 1st to September 15th)
-	isSummerSchedule := (month == time.July) ||
-		(month == time.August) ||
-		(month == time.September && dayOfMonth < 15)
-
-	// Use 7-hour schedule (8:00-15:00) for:
-	// 1. Fridays (MinutesLeft == FridayShiftMinutes)
-	// 2. Days before holidays (DayBeforeHoliday == true)
-	// 3. Summer schedule (July 1st to September 15th)
-	if day.MinutesLeft == FridayShiftMinutes || day.DayBeforeHoliday || isSummerSchedule {
-		shift.ClockIn = "08:00"
-		shift.ClockOut = "15:00"
+	if msg := between(page, "<div class=\"flash flash--wrong\">", "</div>"); msg != "" && len(msg) <= 100 {
+		return &AuthError{Message: msg}
 	}
-
-	return shift
+	if resp.StatusCode != http.StatusOK {
+		return &AuthError{Message: fmt.Sprintf("sign in returned status %d", resp.StatusCode)}
+	}
+	return nil
 }
 
-// addShift adds a shift to Factorial
-func (c *factorialClient) addShift(shift newShift) bool {
-	// Get calendar day (adjusting for 0-based array index)
-	calendarDay := c.calendar[shift.Day-1]
-	date, err := time.Parse("2006-01-02", calendarDay.Date)
-	if err != nil {
-		fmt.Println("Error parsing date:", err)
-		return false
+// Periods returns the attendance periods matching the query
+func (c *Client) Periods(ctx context.Context, query PeriodsQuery) ([]Period, error) {
+	q := url.Values{}
+	setInt(q, "year", query.Year)
+	setInt(q, "month", query.Month)
+	setInt(q, "employee_id", query.EmployeeId)
+	if query.StartOn != "" {
+		q.Set("start_on", query.StartOn)
 	}
+	if query.EndOn != "" {
+		q.Set("end_on", query.EndOn)
+	}
+	var periods []Period
+	if err := c.getJSON(ctx, "/attendance/periods", q, &periods); err != nil {
+		return nil, fmt.Errorf("Error retrieving periods data: %w", err)
+	}
+	return periods, nil
+}
 
-	shift.Date = date.Format("2006-01-02")
-	shift.ReferenceDate = date.Format("2006-01-02")
-
-	// Parse the date to check for summer schedule
-	month := date.Month()
-	dayOfMonth := date.Day()
-
-	// Check if date is in summer schedule (July 1st to September 15th)
-	isSummerSchedule := (month == time.July) ||
-		(month == time.August) ||
-		(month == time.September && dayOfMonth < 15)
+// Calendar returns the calendar of an employee for a month, sorted by day
+func (c *Client) Calendar(ctx context.Context, employeeId, year, month int) ([]CalendarDay, error) {
+	q := url.Values{}
+	setInt(q, "id", employeeId)
+	setInt(q, "year", year)
+	setInt(q, "month", month)
+	var calendar []CalendarDay
+	if err := c.getJSON(ctx, "/attendance/calendar", q, &calendar); err != nil {
+		return nil, fmt.Errorf("Error retrieving calendar data: %w", err)
+	}
+	sort.Slice(calendar, func(i, j int) bool {
+		return calendar[i].Day < calendar[j].Day
+	})
+	return calendar, nil
+}
 
-	// For regular shifts (Monday-Thursday) and not in summer schedule, use breaks
-	if calendarDay.MinutesLeft == RegularShiftMinutes && !isSummerSchedule {
-		return c.addShiftWithBreak(shift, date)
-	}
+// Shifts returns the shifts of an employee for a month
+func (c *Client) Shifts(ctx context.Context, employeeId, year, month int) ([]Shift, error) {
+	q := url.Values{}
+	setInt(q, "employee_id", employeeId)
+	setInt(q, "year", year)
+	setInt(q, "month", month)
+	var shifts []Shift
+	if err := c.getJSON(ctx, "/attendance/shifts", q, &shifts); err != nil {
+		return nil, fmt.Errorf("Error retrieving shifts data: %w", err)
+	}
+	return shifts, nil
+}
 
-	// For Friday shifts, days before holidays, and summer schedule, use direct shift without breaks
-	body, _ := json.Marshal(shift)
-	resp, _ := c.Post(BaseUrl+"/attendance/shifts", "application/json;charset=UTF-8", bytes.NewReader(body))
-	return resp.StatusCode == 201
+// CreateShift records a shift with its clock in and out times
+func (c *Client) CreateShift(ctx context.Context, shift NewShift) error {
+	return c.send(ctx, "POST", "/attendance/shifts", shift, http.StatusCreated)
 }
 
-// addShiftWithBreak adds a shift with break times
-func (c *factorialClient) addShiftWithBreak(shift newShift, date time.Time) bool {
-	// Clock in at 9:00
-	shiftIn := breakShift{
-		EmployeeId:   shift.EmployeeId,
-		LocationType: shift.LocationType,
-		Now:          date.Format("2006-01-02") + "T08:45",
-	}
-	if !c.makeBreakRequest(shiftIn, "/clock_in") {
-		return false
-	}
+// DeleteShift deletes a recorded shift
+func (c *Client) DeleteShift(ctx context.Context, id int64) error {
+	return c.send(ctx, "DELETE", "/attendance/shifts/"+strconv.FormatInt(id, 10), nil, http.StatusNoContent)
+}
 
-	// Break start at 14:15
-	shiftOut := breakShiftOut{
-		EmployeeId: shift.EmployeeId,
-		Now:        date.Format("2006-01-02") + "T14:30",
-	}
-	if !c.makeBreakRequest(shiftOut, "/break_start") {
-		return false
-	}
+// ClockInAt opens a shift at the given time
+func (c *Client) ClockInAt(ctx context.Context, shift BreakShift) error {
+	return c.sendBreak(ctx, "/clock_in", shift)
+}
 
-	// Break end at 15:00
-	shiftOut.Now = date.Format("2006-01-02") + "T15:00"
-	if !c.makeBreakRequest(shiftOut, "/break_end") {
-		return false
-	}
+// BreakStartAt starts a break in the open shift
+func (c *Client) BreakStartAt(ctx context.Context, shift BreakShift) error {
+	shift.LocationType = ""
+	return c.sendBreak(ctx, "/break_start", shift)
+}
 
-	// Clock out at 18:00
-	shiftOut.Now = date.Format("2006-01-02") + "T17:30"
-	return c.makeBreakRequest(shiftOut, "/clock_out")
+// BreakEndAt ends the current break of the open shift. The location is only
+// needed when the work after the break is done elsewhere.
+func (c *Client) BreakEndAt(ctx context.Context, shift BreakShift) error {
+	return c.sendBreak(ctx, "/break_end", shift)
 }
 
-// makeBreakRequest makes a request to the break endpoints
-func (c *factorialClient) makeBreakRequest(data interface{}, endpoint string) bool {
-	body, _ := json.Marshal(data)
-	resp, _ := c.Post(BaseUrl+"/api/2025-10-01/resources/attendance/shifts"+endpoint, "application/json;charset=UTF-8", bytes.NewBuffer(body))
-	if resp.StatusCode != 200 {
-		fmt.Printf("Error in %s request: %d\n", endpoint, resp.StatusCode)
-		return false
-	}
-	return true
+// ClockOutAt closes the open shift
+func (c *Client) ClockOutAt(ctx context.Context, shift BreakShift) error {
+	shift.LocationType = ""
+	return c.sendBreak(ctx, "/clock_out", shift)
 }
 
-// ResetMonth deletes all shifts for the current month
-func (c *factorialClient) ResetMonth() {
-	for _, shift := range c.shifts {
-		date := time.Date(c.year, time.Month(c.month), shift.Day, 0, 0, 0, 0, time.UTC)
-		message := fmt.Sprintf("%s... ", date.Format("02 Jan"))
+func (c *Client) sendBreak(ctx context.Context, endpoint string, shift BreakShift) error {
+	return c.send(ctx, "POST", "/api/2025-10-01/resources/attendance/shifts"+endpoint, shift, http.StatusOK)
+}
 
-		req, _ := http.NewRequest("DELETE", BaseUrl+"/attendance/shifts/"+strconv.Itoa(int(shift.Id)), nil)
-		resp, _ := c.Do(req)
+// timeZone returns the time zone of the client, time.Local if not set
+func (c *Client) timeZone() *time.Location {
+	if c.TimeZone == nil {
+		return time.Local
+	}
+	return c.TimeZone
+}
 
-		if resp.StatusCode != 204 {
-			fmt.Print(fmt.Sprintf("%s ❌ Error when attempting to delete shift: %s - %s\n", message, shift.ClockIn, shift.ClockOut))
-		} else {
-			fmt.Print(fmt.Sprintf("%s ✅ Shift deleted: %s - %s\n", message, shift.ClockIn, shift.ClockOut))
-		}
-		defer resp.Body.Close()
+// timestamp returns a HH:MM time of a YYYY-MM-DD date in the client's time
+// zone as RFC 3339, with the offset in effect on that date so that days
+// around a DST change are recorded at the right hour
+func (c *Client) timestamp(date, clock string) string {
+	t, err := time.ParseInLocation("2006-01-02 15:04", date+" "+clock, c.timeZone())
+	if err != nil {
+		return date + "T" + clock
 	}
-	fmt.Println("done!")
+	return t.Format(time.RFC3339)
 }
 
 // Helper functions for API calls
-func (c *factorialClient) login(email, password string) error {
-	getCSRFToken := func(resp *http.Response) string {
-		data, _ := io.ReadAll(resp.Body)
-		err := resp.Body.Close()
-		if err != nil {
-			return "Login error"
-		}
-		start := strings.Index(string(data), "<meta name=\"csrf-token\" content=\"") + 33
-		end := strings.Index(string(data)[start:], "\" />")
-		return string(data)[start : start+end]
+func (c *Client) baseUrl() string {
+	if c.BaseUrl == "" {
+		return BaseUrl
 	}
+	return strings.TrimSuffix(c.BaseUrl, "/")
+}
 
-	getLoginError := func(resp *http.Response) string {
-		data, _ := io.ReadAll(resp.Body)
-		err := resp.Body.Close()
-		if err != nil {
-			return "Login error"
-		}
-		start := strings.Index(string(data), "<div class=\"flash flash--wrong\">") + 32
-		if start < 0 {
-			return ""
-		}
-		end := strings.Index(string(data)[start:], "</div>")
-		if start < 0 || end-start > 100 {
-			return ""
-		}
-		return string(data)[start : start+end]
+func (c *Client) request(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseUrl()+path, body)
+	if err != nil {
+		return nil, err
 	}
-
-	resp, _ := c.Get(BaseUrl + "/users/sign_in")
-	csrfToken := getCSRFToken(resp)
-	body := url.Values{
-		"authenticity_token": {csrfToken},
-		"return_host":        {"factorialhr.es"},
-		"user[email]":        {email},
-		"user[password]":     {password},
-		"user[remember_me]":  {"0"},
-		"commit":             {"Sign in"},
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
 	}
-	resp, _ = c.PostForm(BaseUrl+"/users/sign_in", body)
-	if err := getLoginError(resp); err != "" {
-		return errors.New(err)
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, &TransportError{Method: method, Path: req.URL.Path, Err: err}
 	}
-	return nil
+	return resp, nil
 }
 
-func (c *factorialClient) setPeriodId() error {
-	err := errors.New("Could not find the specified year/month in the available periods (" + strconv.Itoa(c.month) + "/" + strconv.Itoa(c.year) + ")")
-	resp, _ := c.Get(BaseUrl + "/attendance/periods?year=" + strconv.Itoa(c.year) + "&month=" + strconv.Itoa(c.month))
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, v interface{}) error {
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	resp, err := c.request(ctx, "GET", path, "", nil)
+	if err != nil {
 		return err
 	}
-	var periods []period
-	body, _ := io.ReadAll(resp.Body)
-	err = json.Unmarshal(body, &periods)
+	body, err := readBody(resp)
 	if err != nil {
 		return err
 	}
-	for _, p := range periods {
-		if p.Year == c.year && p.Month == c.month {
-			c.employeeId = p.EmployeeId
-			c.periodId = p.Id
-			return nil
-		}
+	if err := checkStatus(resp.Request, resp, body, http.StatusOK); err != nil {
+		return err
+	}
+	if resp.Request.URL.Path == "/users/sign_in" {
+		// Expired sessions may be redirected to the sign in page
+		return &AuthError{Message: "session expired"}
 	}
-	return err
+	if err := json.Unmarshal([]byte(body), v); err != nil {
+		return fmt.Errorf("decoding %s response: %w", resp.Request.URL.Path, err)
+	}
+	return nil
 }
 
-func (c *factorialClient) setCalendar() error {
-	u, _ := url.Parse(BaseUrl + "/attendance/calendar")
-	q := u.Query()
-	q.Set("id", strconv.Itoa(c.employeeId))
-	q.Set("year", strconv.Itoa(c.year))
-	q.Set("month", strconv.Itoa(c.month))
-	u.RawQuery = q.Encode()
-	resp, _ := c.Get(u.String())
-	if resp.StatusCode != 200 {
-		return errors.New("Error retrieving calendar data")
+func (c *Client) send(ctx context.Context, method, path string, data interface{}, status int) error {
+	var body io.Reader
+	contentType := ""
+	if data != nil {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+		contentType = "application/json;charset=UTF-8"
 	}
-	defer resp.Body.Close()
-	body, _ := io.ReadAll(resp.Body)
-	err := json.Unmarshal(body, &c.calendar)
+	resp, err := c.request(ctx, method, path, contentType, body)
 	if err != nil {
 		return err
 	}
-	sort.Slice(c.calendar, func(i, j int) bool {
-		return c.calendar[i].Day < c.calendar[j].Day
-	})
-	err = c.CheckHourCalendar(c.calendar)
+	page, err := readBody(resp)
 	if err != nil {
 		return err
 	}
-	return nil
+	return checkStatus(resp.Request, resp, page, status)
 }
 
-func (c *factorialClient) setShifts() error {
-	u, _ := url.Parse(BaseUrl + "/attendance/shifts")
-	q := u.Query()
-	q.Set("employee_id", strconv.Itoa(c.employeeId))
-	q.Set("year", strconv.Itoa(c.year))
-	q.Set("month", strconv.Itoa(c.month))
-	u.RawQuery = q.Encode()
-	resp, _ := c.Get(u.String())
-	if resp.StatusCode != 200 {
-		return errors.New("Error retrieving shifts data")
-	}
+func readBody(resp *http.Response) (string, error) {
 	defer resp.Body.Close()
-	body, _ := io.ReadAll(resp.Body)
-	err := json.Unmarshal(body, &c.shifts)
+	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return "", &TransportError{Method: resp.Request.Method, Path: resp.Request.URL.Path, Err: err}
 	}
-	return nil
+	return string(data), nil
 }
 
-func (c *factorialClient) clockedIn(day int, inputShift newShift) (bool, string) {
-	clockIn, _ := strconv.Atoi(strings.Join(strings.Split(inputShift.ClockIn, ":"), ""))
-	clockOut, _ := strconv.Atoi(strings.Join(strings.Split(inputShift.ClockOut, ":"), ""))
-	for _, shift := range c.shifts {
-		if shift.Day == day {
-			shiftClockIn, _ := strconv.Atoi(strings.Join(strings.Split(shift.ClockIn, ":"), ""))
-			shiftClockOut, _ := strconv.Atoi(strings.Join(strings.Split(shift.ClockOut, ":"), ""))
-			if (clockIn < shiftClockIn && shiftClockIn < clockOut) ||
-				(clockIn < shiftClockOut && shiftClockOut < clockOut) ||
-				(shiftClockIn <= clockIn && shiftClockOut >= clockOut) {
-				return true, strings.Join([]string{shift.ClockIn, shift.ClockOut}, " - ")
-			}
-		}
+// between returns the text between the first start marker and the next end
+// marker, or an empty string if they're not found
+func between(s, start, end string) string {
+	i := strings.Index(s, start)
+	if i < 0 {
+		return ""
 	}
-	return false, ""
-}
-
-func handleError(spinner *spinner.Spinner, err error) {
-	if err != nil {
-		spinner.Stop()
-		log.Fatal(err)
+	s = s[i+len(start):]
+	j := strings.Index(s, end)
+	if j < 0 {
+		return ""
 	}
+	return s[:j]
 }
 
-// CheckHourCalendar retrieves and sets the minutes left for each day in the calendar
-func (c *factorialClient) CheckHourCalendar(calendar []calendarDay) error {
-	u, _ := url.Parse(BaseUrl + "/attendance/periods")
-	q := u.Query()
-	q.Set("year", strconv.Itoa(c.year))
-	q.Set("month", strconv.Itoa(c.month))
-	q.Set("employee_id", strconv.Itoa(c.employeeId))
-	q.Set("start_on", c.calendar[0].Date)
-	q.Set("end_on", c.calendar[len(c.calendar)-1].Date)
-	u.RawQuery = q.Encode()
-	resp, _ := c.Get(u.String())
-	if resp.StatusCode != 200 {
-		return errors.New("Error retrieving calendar data")
+func setInt(q url.Values, key string, value int) {
+	if value != 0 {
+		q.Set(key, strconv.Itoa(value))
 	}
-	defer resp.Body.Close()
-	var minutesLeft []Period
-	body, _ := io.ReadAll(resp.Body)
-	err := json.Unmarshal(body, &minutesLeft)
-	if err != nil {
-		return err
-	}
-	for i := range c.calendar {
-		c.calendar[i].MinutesLeft = minutesLeft[0].EstimatedRegularMinutesDistribution[i]
-	}
-	return nil
 }
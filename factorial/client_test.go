@@ -0,0 +1,166 @@
+package factorial_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alejoar/factorialsucks/factorial"
+	"github.com/alejoar/factorialsucks/factorial/factorialtest"
+)
+
+const (
+	testEmail    = "jane@example.com"
+	testPassword = "secret"
+)
+
+// newServer starts a fake server with a holiday and a leave in October 2026
+func newServer(t *testing.T) *factorialtest.Server {
+	t.Helper()
+	s := factorialtest.NewServer(testEmail, testPassword)
+	t.Cleanup(s.Close)
+	s.SetHoliday("2026-10-12")
+	s.SetLeave("2026-10-20", "Vacation")
+	return s
+}
+
+// login returns a client of the server signed in
+func login(t *testing.T, s *factorialtest.Server) *factorial.Client {
+	t.Helper()
+	c := s.NewClient()
+	if err := c.Login(context.Background(), testEmail, testPassword); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	return c
+}
+
+// loadMonth loads October 2026
+func loadMonth(t *testing.T, c *factorial.Client) *factorial.Month {
+	t.Helper()
+	m, err := c.LoadMonth(context.Background(), 2026, 10)
+	if err != nil {
+		t.Fatalf("LoadMonth: %v", err)
+	}
+	return m
+}
+
+func TestLogin(t *testing.T) {
+	s := newServer(t)
+	ctx := context.Background()
+
+	if err := s.NewClient().Login(ctx, testEmail, testPassword); err != nil {
+		t.Errorf("Login with good credentials: %v", err)
+	}
+
+	err := s.NewClient().Login(ctx, testEmail, "wrong")
+	var authErr *factorial.AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Login with bad credentials = %v, want an *AuthError", err)
+	}
+	if authErr.Message != "Invalid email or password." {
+		t.Errorf("AuthError message = %q", authErr.Message)
+	}
+
+	if _, err := s.NewClient().LoadMonth(ctx, 2026, 10); !errors.As(err, &authErr) {
+		t.Errorf("LoadMonth without logging in = %v, want an *AuthError", err)
+	}
+}
+
+func TestClockInMonth(t *testing.T) {
+	s := newServer(t)
+	c := login(t, s)
+	ctx := context.Background()
+
+	var created int
+	err := c.ClockIn(ctx, loadMonth(t, c), factorial.ClockInOptions{}, func(r factorial.DayResult) {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.Date.Format("2006-01-02"), r.Err)
+		}
+		if !r.Skipped {
+			created++
+		}
+	})
+	if err != nil {
+		t.Fatalf("ClockIn: %v", err)
+	}
+	// 22 weekdays less the holiday and the leave
+	if created != 20 {
+		t.Errorf("created %d days, want 20", created)
+	}
+
+	tests := []struct {
+		date   string
+		shifts []string
+	}{
+		{"2026-10-01", []string{"08:45 - 14:30", "15:00 - 17:30"}},
+		{"2026-10-02", []string{"08:00 - 15:00"}},
+		{"2026-10-03", nil},
+		{"2026-10-12", nil},
+		{"2026-10-20", nil},
+		{"2026-10-30", []string{"08:00 - 15:00"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, shift := range s.Shifts(tt.date) {
+			got = append(got, shift.ClockIn+" - "+shift.ClockOut)
+		}
+		if !equalStrings(got, tt.shifts) {
+			t.Errorf("%s shifts = %q, want %q", tt.date, got, tt.shifts)
+		}
+	}
+	// 15 days with a lunch break and 5 Fridays without
+	if n := len(s.Shifts("")); n != 35 {
+		t.Errorf("stored %d shifts, want 35", n)
+	}
+
+	// Every day is skipped the second time
+	err = c.ClockIn(ctx, loadMonth(t, c), factorial.ClockInOptions{}, func(r factorial.DayResult) {
+		if !r.Skipped {
+			t.Errorf("%s clocked in again: %s - %s", r.Date.Format("2006-01-02"), r.Shift.ClockIn, r.Shift.ClockOut)
+		}
+	})
+	if err != nil {
+		t.Fatalf("ClockIn again: %v", err)
+	}
+	if n := len(s.Shifts("")); n != 35 {
+		t.Errorf("stored %d shifts after the second run, want 35", n)
+	}
+}
+
+func TestResetMonth(t *testing.T) {
+	s := newServer(t)
+	c := login(t, s)
+	ctx := context.Background()
+	s.AddShift("2026-10-05", "09:00", "13:00")
+	s.AddShift("2026-10-06", "09:00", "17:00")
+	s.AddShift("2026-11-02", "09:00", "17:00")
+
+	var deleted int
+	err := c.ResetMonth(ctx, loadMonth(t, c), func(r factorial.ResetResult) {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.Date.Format("2006-01-02"), r.Err)
+		}
+		deleted++
+	})
+	if err != nil {
+		t.Fatalf("ResetMonth: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("deleted %d shifts, want 2", deleted)
+	}
+	if shifts := s.Shifts(""); len(shifts) != 1 || shifts[0].Day != 2 {
+		t.Errorf("shifts left = %+v, want the one of November", shifts)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,293 @@
+package factorial
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// ClockInOptions configures a clock in run
+type ClockInOptions struct {
+	// ClockIn and ClockOut are used for days no schedule rule matches
+	ClockIn    string
+	ClockOut   string
+	Schedule   *Schedule
+	TodayOnly  bool
+	UntilToday bool
+	DryRun     bool
+	// Recovery applies when a shift with breaks fails half way, defaults to
+	// RecoveryRollback
+	Recovery Recovery
+	// Now is the reference time for TodayOnly and UntilToday, defaults to
+	// time.Now(). Today is the day it is in the client's time zone.
+	Now time.Time
+	// Jitter moves the clock in and breaks of each shift by up to that many
+	// minutes, keeping the worked minutes at the day's MinutesLeft. The same
+	// Seed gives the same times.
+	Jitter int
+	Seed   int64
+	// Location is where every shift is worked from, overriding the location
+	// rules of the schedule
+	Location string
+	// Overlap is what to do with days whose shifts overlap the ones already
+	// recorded, defaults to OverlapSkip
+	Overlap OverlapPolicy
+	// Backup is the file the shifts deleted by OverlapReplace are written to
+	// before deleting any, defaults to DefaultBackupPath
+	Backup string
+	// Workers is how many days are recorded at once, defaults to 1. They
+	// share the client's retries and rate limit. The clock in, break and
+	// clock out requests of days with breaks still go one day at a time,
+	// Factorial only allows one open shift per employee.
+	Workers int
+}
+
+// DayResult is the outcome of clocking in a single day
+type DayResult struct {
+	Date    time.Time
+	Skipped bool
+	Reason  string
+	Shift   NewShift
+	Breaks  []Segment
+	DryRun  bool
+	Err     error
+	// Recovery says how a shift with breaks that failed half way was dealt
+	// with
+	Recovery string
+	// Overlap says how shifts already recorded on the day were dealt with,
+	// and Backup is the file the shifts it replaced were written to
+	Overlap string
+	Backup  string
+}
+
+// Segments returns the worked time windows of the day, the shift without its
+// breaks, with their location
+func (r DayResult) Segments() []Segment {
+	var segments []Segment
+	start := r.Shift.ClockIn
+	for _, b := range r.Breaks {
+		segments = append(segments, Segment{Start: start, End: b.Start})
+		start = b.End
+	}
+	segments = append(segments, Segment{Start: start, End: r.Shift.ClockOut})
+	for i := range segments {
+		segments[i].Location = r.Shift.LocationType
+		if i < len(r.Shift.Locations) {
+			segments[i].Location = r.Shift.Locations[i]
+		}
+	}
+	return segments
+}
+
+// ClockIn adds shifts for every day of the month within its range, calling
+// report with the result of each day in calendar order. Days that fail don't
+// stop the run, they're returned together in a *RunError.
+func (c *Client) ClockIn(ctx context.Context, m *Month, opts ClockInOptions, report func(DayResult)) error {
+	return c.ClockInMonths(ctx, []*Month{m}, opts, report)
+}
+
+// ClockInMonths clocks in the months in order as a single run, the days
+// that fail in any of them are returned together in a *RunError. Up to
+// opts.Workers days are recorded at once, each day's requests in order, and
+// report is called in calendar order all the same.
+func (c *Client) ClockInMonths(ctx context.Context, months []*Month, opts ClockInOptions, report func(DayResult)) error {
+	if opts.Schedule == nil {
+		opts.Schedule = DefaultSchedule()
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	opts.Now = opts.Now.In(c.timeZone())
+	if opts.Recovery == "" {
+		opts.Recovery = RecoveryRollback
+	}
+	if opts.Overlap == "" {
+		opts.Overlap = OverlapSkip
+	}
+
+	var days []plannedDay
+	for _, m := range months {
+		for _, day := range m.Calendar {
+			if m.InRange(day.Day) {
+				days = append(days, m.planDay(day, opts))
+			}
+		}
+	}
+
+	// The shifts replaced are backed up before deleting any
+	if !opts.DryRun {
+		backup := c.NewBackup()
+		for _, d := range days {
+			backup.Add(d.month, d.replaced)
+		}
+		if len(backup.Shifts) > 0 {
+			path, err := saveReplaced(backup, opts.Backup)
+			if err != nil {
+				return err
+			}
+			for i := range days {
+				if len(days[i].replaced) > 0 {
+					days[i].result.Backup = path
+				}
+			}
+		}
+	}
+
+	var failed []*DayError
+	runOrdered(ctx, len(days), opts.Workers, func(i int) {
+		d := &days[i]
+		if d.result.Skipped || opts.DryRun {
+			return
+		}
+		d.result.Recovery, d.result.Err = c.replaceDay(ctx, d.month, d.result.Shift, d.result.Breaks, d.replaced, opts.Recovery)
+	}, func(i int) {
+		if r := days[i].result; r.Err != nil {
+			failed = append(failed, &DayError{Date: r.Date, Err: r.Err})
+		}
+		report(days[i].result)
+	})
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return &RunError{Errors: failed}
+	}
+	return nil
+}
+
+// plannedDay is a day of a clock in run and the shifts to delete before
+// recording it
+type plannedDay struct {
+	month    *Month
+	result   DayResult
+	replaced []Shift
+}
+
+// planDay works out the shift of a day, or why it's skipped, without
+// sending anything
+func (m *Month) planDay(day CalendarDay, opts ClockInOptions) plannedDay {
+	p := plannedDay{month: m, result: DayResult{Date: m.Date(day.Day), DryRun: opts.DryRun}}
+	result := &p.result
+
+	// Skip if conditions are not met
+	if skip, reason := m.shouldSkipDay(day, result.Date, opts); skip {
+		result.Skipped = true
+		result.Reason = reason
+		return p
+	}
+
+	// Create the shift
+	var err error
+	result.Shift, result.Breaks, err = m.createShift(day, opts)
+	if err != nil {
+		result.Skipped = true
+		result.Reason = err.Error()
+		return p
+	}
+
+	// Check the shift against the ones already recorded
+	var reason string
+	if opts.Overlap == OverlapFillGaps {
+		result.Shift, result.Breaks, result.Overlap, reason = m.fillGaps(day, result.Shift, result.Breaks)
+	} else {
+		result.Shift, result.Breaks, p.replaced, result.Overlap, reason = m.applyOverlap(day.Day, result.Shift, result.Breaks, opts.Overlap)
+	}
+	if reason != "" {
+		result.Skipped = true
+		result.Reason = reason
+		return p
+	}
+	if opts.Overlap == OverlapFillGaps && result.Overlap != "" {
+		date, _ := time.Parse("2006-01-02", day.Date)
+		setLocations(&result.Shift, result.Breaks, date, opts.Schedule, opts.Location)
+	}
+	return p
+}
+
+// collectDayErrors appends the days of a *RunError to failed, any other error
+// is returned as is
+func collectDayErrors(failed []*DayError, err error) ([]*DayError, error) {
+	if runErr, ok := err.(*RunError); ok {
+		return append(failed, runErr.Errors...), nil
+	}
+	return failed, err
+}
+
+// shouldSkipDay determines if a day should be skipped and why
+func (m *Month) shouldSkipDay(day CalendarDay, date time.Time, opts ClockInOptions) (bool, string) {
+	// Check for leaves, days with partial leaves are worked for the time left
+	if day.IsLeave && !day.PartialLeave() {
+		return true, day.LeaveName
+	}
+
+	// Check for non-laborable days
+	if !day.IsLaborable {
+		return true, date.Format("Monday")
+	}
+
+	// Check for today-only flag, today being the date in the time zone of Now
+	today := opts.Now.Format("2006-01-02")
+	if opts.TodayOnly && date.Format("2006-01-02") != today {
+		return true, "Skipping: --today"
+	}
+
+	// Check for until-today flag
+	if opts.UntilToday && date.Format("2006-01-02") > today {
+		return true, "Skipping: --until-today"
+	}
+
+	return false, ""
+}
+
+// createShift creates a shift for the given day using the first matching
+// schedule rule, which may work out its times from the day's expected
+// minutes, falling back to the --clock-in/--clock-out times. It applies the
+// jitter, moves the work out of partial leaves, failing if it doesn't fit,
+// and sets the location of each segment.
+func (m *Month) createShift(day CalendarDay, opts ClockInOptions) (NewShift, []Segment, error) {
+	shift := m.newShift(day, opts.ClockIn, opts.ClockOut)
+	var breaks []Segment
+
+	date, _ := time.Parse("2006-01-02", day.Date)
+	if rule, ok := opts.Schedule.match(day, date); ok {
+		shift.ClockIn, shift.ClockOut, breaks, _ = rule.times(day.MinutesLeft)
+	}
+	if opts.Jitter > 0 {
+		shift, breaks = jitter(shift, breaks, int(math.Round(day.MinutesLeft)), opts.Jitter, opts.Seed)
+	}
+	shift, breaks, err := fitLeaves(shift, breaks, day)
+	if err != nil {
+		return shift, breaks, err
+	}
+	setLocations(&shift, breaks, date, opts.Schedule, opts.Location)
+	return shift, breaks, nil
+}
+
+// newShift returns the payload of a shift worked from home on a day of the
+// month, see setLocations
+func (m *Month) newShift(day CalendarDay, clockIn, clockOut string) NewShift {
+	return NewShift{
+		ClockIn:                          clockIn,
+		ClockOut:                         clockOut,
+		Day:                              day.Day,
+		EmployeeId:                       m.EmployeeId,
+		Workable:                         true,
+		LocationType:                     "work_from_home",
+		Source:                           "desktop",
+		TimeSettingsBreakConfigurationId: nil,
+		Minutes:                          nil,
+		Date:                             day.Date,
+		ReferenceDate:                    day.Date,
+	}
+}
+
+// addShift adds a shift to Factorial
+func (c *Client) addShift(ctx context.Context, m *Month, shift NewShift, breaks []Segment, recovery Recovery) (string, error) {
+	// Shifts with breaks go through the clock in/out endpoints
+	if len(breaks) > 0 {
+		return c.addShiftWithBreak(ctx, m, shift, breaks, recovery)
+	}
+
+	// Shifts without breaks are created directly
+	return "", c.CreateShift(ctx, shift)
+}
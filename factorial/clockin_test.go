@@ -0,0 +1,278 @@
+package factorial_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alejoar/factorialsucks/factorial"
+)
+
+// clockIn runs ClockIn on October 2026 and returns the days clocked in
+func clockIn(t *testing.T, c *factorial.Client, opts factorial.ClockInOptions) ([]string, error) {
+	t.Helper()
+	var days []string
+	err := c.ClockIn(context.Background(), loadMonth(t, c), opts, func(r factorial.DayResult) {
+		if !r.Skipped && r.Err == nil {
+			days = append(days, r.Date.Format("2006-01-02"))
+		}
+	})
+	return days, err
+}
+
+func TestClockInAcrossDST(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skip("no time zone data:", err)
+	}
+	s := newServer(t)
+	s.TimeZone = madrid
+	c := login(t, s)
+
+	// The breaks go through the clock in endpoints, which check the offset
+	days, err := clockIn(t, c, factorial.ClockInOptions{UntilToday: true, Now: time.Date(2026, 10, 26, 12, 0, 0, 0, madrid)})
+	if err != nil {
+		t.Fatalf("ClockIn: %v", err)
+	}
+	if last := days[len(days)-1]; last != "2026-10-26" {
+		t.Errorf("last day clocked in = %s, want 2026-10-26", last)
+	}
+	for _, date := range []string{"2026-10-22", "2026-10-26"} {
+		shifts := s.Shifts(date)
+		if len(shifts) != 2 || shifts[0].ClockIn != "08:45" || shifts[1].ClockOut != "17:30" {
+			t.Errorf("%s shifts = %+v, want 08:45 - 17:30 with a break", date, shifts)
+		}
+	}
+
+	// A client in another time zone sends the wrong offset
+	s2 := newServer(t)
+	s2.TimeZone = madrid
+	c2 := login(t, s2)
+	c2.TimeZone = time.UTC
+	_, err = clockIn(t, c2, factorial.ClockInOptions{TodayOnly: true, Now: time.Date(2026, 10, 22, 12, 0, 0, 0, time.UTC)})
+	var validationErr *factorial.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("ClockIn with the wrong offset = %v, want a *ValidationError", err)
+	}
+}
+
+func TestClockInTodayAheadOfHost(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("no time zone data:", err)
+	}
+	// 23:30 on Monday in UTC is already Tuesday in Tokyo
+	now := time.Date(2026, 10, 5, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		opts factorial.ClockInOptions
+		want []string
+	}{
+		{"today", factorial.ClockInOptions{TodayOnly: true, Now: now}, []string{"2026-10-06"}},
+		{"until today", factorial.ClockInOptions{UntilToday: true, Now: now}, []string{"2026-10-01", "2026-10-02", "2026-10-05", "2026-10-06"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t)
+			s.TimeZone = tokyo
+			days, err := clockIn(t, login(t, s), tt.opts)
+			if err != nil {
+				t.Fatalf("ClockIn: %v", err)
+			}
+			if !equalStrings(days, tt.want) {
+				t.Errorf("days clocked in = %q, want %q", days, tt.want)
+			}
+		})
+	}
+}
+
+func TestClockInReplace(t *testing.T) {
+	s := newServer(t)
+	s.AddShift("2026-10-05", "09:00", "13:00")
+	s.AddShift("2026-10-06", "10:00", "11:00")
+	c := login(t, s)
+	dir := t.TempDir()
+
+	// replace clocks in today, returning its result and the backup written
+	replace := func(day int) (factorial.DayResult, *factorial.Backup, error) {
+		var result factorial.DayResult
+		path := filepath.Join(dir, fmt.Sprintf("replaced-%d.json", day))
+		err := c.ClockIn(context.Background(), loadMonth(t, c), factorial.ClockInOptions{
+			TodayOnly: true,
+			Now:       time.Date(2026, 10, day, 12, 0, 0, 0, time.Local),
+			Overlap:   factorial.OverlapReplace,
+			Backup:    path,
+		}, func(r factorial.DayResult) {
+			if !r.Skipped {
+				result = r
+			}
+		})
+		if result.Backup != path {
+			t.Errorf("%d: backup = %q, want %q", day, result.Backup, path)
+		}
+		backup, loadErr := factorial.LoadBackup(path)
+		if loadErr != nil {
+			t.Fatalf("%d: LoadBackup: %v", day, loadErr)
+		}
+		return result, backup, err
+	}
+
+	tests := []struct {
+		day    int
+		fail   bool
+		backup string
+		shifts []string
+	}{
+		// The shift can't be recorded after the one replaced was deleted
+		{5, true, "2026-10-05 09:00 - 13:00", []string{"09:00 - 13:00"}},
+		{6, false, "2026-10-06 10:00 - 11:00", []string{"08:45 - 14:30", "15:00 - 17:30"}},
+	}
+	for _, tt := range tests {
+		if tt.fail {
+			s.Fail("POST", "/api/2025-10-01/resources/attendance/shifts/clock_in", 1, http.StatusUnprocessableEntity, `{"error":"Something went wrong"}`)
+		}
+		result, backup, err := replace(tt.day)
+		if tt.fail {
+			var runErr *factorial.RunError
+			if !errors.As(err, &runErr) || !strings.Contains(result.Recovery, "created again") {
+				t.Errorf("%d: ClockIn = %v (%s), want it failed and the replaced shift created again", tt.day, err, result.Recovery)
+			}
+		} else if err != nil {
+			t.Errorf("%d: ClockIn: %v", tt.day, err)
+		}
+
+		var saved []string
+		for _, shift := range backup.Shifts {
+			saved = append(saved, shift.Date+" "+shift.ClockIn+" - "+shift.ClockOut)
+		}
+		if !equalStrings(saved, []string{tt.backup}) {
+			t.Errorf("%d: backup = %q, want %q", tt.day, saved, tt.backup)
+		}
+		date := fmt.Sprintf("2026-10-%02d", tt.day)
+		var got []string
+		for _, shift := range s.Shifts(date) {
+			got = append(got, shift.ClockIn+" - "+shift.ClockOut)
+		}
+		if !equalStrings(got, tt.shifts) {
+			t.Errorf("%s shifts = %q, want %q", date, got, tt.shifts)
+		}
+	}
+}
+
+func TestClockInWorkers(t *testing.T) {
+	s := newServer(t)
+	c := login(t, s)
+	// A break fails, whichever day it belongs to is rolled back
+	s.Fail("POST", "/api/2025-10-01/resources/attendance/shifts/break_end", 1, http.StatusUnprocessableEntity, `{"error":"Something went wrong"}`)
+
+	var dates []string
+	var failed factorial.DayResult
+	err := c.ClockIn(context.Background(), loadMonth(t, c), factorial.ClockInOptions{Workers: 4}, func(r factorial.DayResult) {
+		dates = append(dates, r.Date.Format("2006-01-02"))
+		if r.Err != nil {
+			failed = r
+		}
+	})
+	// Another day clocking in while one is open would fail too
+	var runErr *factorial.RunError
+	if !errors.As(err, &runErr) || len(runErr.Errors) != 1 {
+		t.Fatalf("ClockIn = %v, want a day failed", err)
+	}
+	if !sort.StringsAreSorted(dates) || len(dates) != 31 {
+		t.Errorf("reported %q, want every day in order", dates)
+	}
+
+	date := failed.Date.Format("2006-01-02")
+	if shifts := s.Shifts(date); len(shifts) != 0 {
+		t.Errorf("%s shifts = %+v, want them rolled back", date, shifts)
+	}
+	// Days with breaks keep going through the clock in endpoints
+	want := 35 - len(failed.Breaks) - 1
+	if n := len(s.Shifts("")); n != want {
+		t.Errorf("stored %d shifts, want %d", n, want)
+	}
+	clockIns := 0
+	for _, r := range s.Requests() {
+		if strings.Contains(r, "/shifts/clock_in") {
+			clockIns++
+		}
+	}
+	if clockIns != 15 {
+		t.Errorf("clocked in %d times, want once per day with breaks (15)", clockIns)
+	}
+}
+
+func TestClockInPartialLeaves(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected float64 // minutes expected before the leave, the default when 0
+		leave    factorial.CalendarLeave
+		want     string
+		skipped  string // the reason the day is skipped
+	}{
+		{
+			// Half of the day is off and the rest is worked after it
+			name:  "morning half day without minutes",
+			leave: factorial.CalendarLeave{Name: "Morning off", HalfDay: factorial.HalfDayMorning},
+			want:  "12:53-17:01",
+		},
+		{
+			name:  "timed leave in the middle of the day",
+			leave: factorial.CalendarLeave{Name: "Doctor", StartTime: "10:00", EndTime: "12:00", Minutes: 120},
+			want:  "08:45-10:00, 12:00-17:00",
+		},
+		{
+			// The lunch break at 14:30 falls in the leave and is dropped
+			name:     "leave covering the lunch break",
+			expected: 615,
+			leave:    factorial.CalendarLeave{Name: "Doctor", StartTime: "14:00", EndTime: "15:30", Minutes: 90},
+			want:     "08:45-14:00, 15:30-19:00",
+		},
+		{
+			name:     "time left that no longer fits before midnight",
+			expected: 1200,
+			leave:    factorial.CalendarLeave{Name: "Training", StartTime: "09:00", EndTime: "21:00", Minutes: 720},
+			skipped:  "Training: the time left doesn't fit in the day",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t)
+			if tt.expected > 0 {
+				s.SetExpectedMinutes("2026-10-06", tt.expected)
+			}
+			s.SetPartialLeave("2026-10-06", tt.leave)
+			c := login(t, s)
+
+			var result factorial.DayResult
+			err := c.ClockIn(context.Background(), loadMonth(t, c), factorial.ClockInOptions{
+				TodayOnly: true,
+				Now:       time.Date(2026, 10, 6, 12, 0, 0, 0, time.Local),
+			}, func(r factorial.DayResult) {
+				if r.Date.Day() == 6 {
+					result = r
+				}
+			})
+			if err != nil {
+				t.Fatalf("ClockIn: %v", err)
+			}
+			if result.Reason != tt.skipped {
+				t.Errorf("skipped because %q, want %q", result.Reason, tt.skipped)
+			}
+			var got []string
+			for _, shift := range s.Shifts("2026-10-06") {
+				got = append(got, shift.ClockIn+"-"+shift.ClockOut)
+			}
+			if strings.Join(got, ", ") != tt.want {
+				t.Errorf("shifts = %q, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package factorial
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DaemonOptions configures a daemon run
+type DaemonOptions struct {
+	// ClockIn and ClockOut are used for days no schedule rule matches
+	ClockIn  string
+	ClockOut string
+	Schedule *Schedule
+	// Jitter and Seed move the times of each day as in ClockInOptions. A
+	// restarted daemon only recognizes the steps it recorded with the same
+	// seed, so 0 uses one derived from the employee id.
+	Jitter int
+	Seed   int64
+	// Location overrides the location rules of the schedule
+	Location string
+	// MaxDelay is how late the daemon can start a day that has nothing
+	// recorded yet. Later days are left to be back-filled with a clock in run.
+	// Defaults to 15 minutes.
+	MaxDelay time.Duration
+	// RetryInterval is the wait before retrying a step that failed or a day
+	// that couldn't be loaded, defaults to a minute
+	RetryInterval time.Duration
+	// Now returns the current time, defaults to time.Now. Days follow the
+	// client's time zone.
+	Now func() time.Time
+}
+
+// Daemon actions
+const (
+	DaemonPlanned = "planned" // the day's steps are scheduled
+	DaemonResumed = "resumed" // part of the day was already recorded
+	DaemonWaiting = "waiting" // sleeping until the next step or day
+	DaemonSent    = "sent"    // a step was recorded
+	DaemonFailed  = "failed"  // a step or the day's data failed
+	DaemonSkipped = "skipped" // nothing to do on the day
+	DaemonDone    = "done"    // every step of the day is recorded
+)
+
+// DaemonEvent is something the daemon did or is about to do
+type DaemonEvent struct {
+	Time   time.Time
+	Date   time.Time
+	Action string
+	// Endpoint and At are the step concerned, if any
+	Endpoint string
+	At       string
+	Message  string
+	Err      error
+}
+
+// Daemon clocks in live: each working day it calls the clock in, break and
+// clock out endpoints at the times of the schedule, skipping leaves and
+// holidays. Steps Factorial already recorded are not sent again, so a
+// restarted daemon picks up where it left off. It runs until ctx is done or
+// the session is rejected, calling log with every action.
+func (c *Client) Daemon(ctx context.Context, opts DaemonOptions, log func(DaemonEvent)) error {
+	if opts.Schedule == nil {
+		opts.Schedule = DefaultSchedule()
+	}
+	if opts.MaxDelay == 0 {
+		opts.MaxDelay = 15 * time.Minute
+	}
+	if opts.RetryInterval == 0 {
+		opts.RetryInterval = time.Minute
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	for {
+		now := opts.Now().In(c.timeZone())
+		date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if err := c.daemonDay(ctx, date, opts, log); err != nil {
+			var authErr *AuthError
+			if ctx.Err() != nil || errors.As(err, &authErr) {
+				return err
+			}
+			log(DaemonEvent{Time: opts.Now(), Date: date, Action: DaemonFailed, Err: err,
+				Message: fmt.Sprintf("retrying in %s", opts.RetryInterval)})
+			if err := sleep(ctx, opts.RetryInterval); err != nil {
+				return err
+			}
+			continue
+		}
+
+		next := date.AddDate(0, 0, 1)
+		log(DaemonEvent{Time: opts.Now(), Date: next, Action: DaemonWaiting, Message: "next day"})
+		if err := waitUntil(ctx, opts.Now, next); err != nil {
+			return err
+		}
+	}
+}
+
+// daemonDay runs the steps of a day still to be done. Only errors loading
+// the day are returned, a step that can't be recorded gives up the day.
+func (c *Client) daemonDay(ctx context.Context, date time.Time, opts DaemonOptions, log func(DaemonEvent)) error {
+	event := func(action string) DaemonEvent {
+		return DaemonEvent{Time: opts.Now(), Date: date, Action: action}
+	}
+
+	m, err := c.LoadMonth(ctx, date.Year(), int(date.Month()))
+	if err != nil {
+		return err
+	}
+	day, ok := m.calendarDay(date.Day())
+	skip := event(DaemonSkipped)
+	switch {
+	case !ok:
+		skip.Message = "Not in the calendar"
+	case day.IsLeave && !day.PartialLeave():
+		skip.Message = day.LeaveName
+	case !day.IsLaborable:
+		skip.Message = date.Format("Monday")
+	}
+	if skip.Message != "" {
+		log(skip)
+		return nil
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = int64(m.EmployeeId)
+	}
+	shift, breaks, err := m.createShift(day, ClockInOptions{
+		ClockIn:  opts.ClockIn,
+		ClockOut: opts.ClockOut,
+		Schedule: opts.Schedule,
+		Jitter:   opts.Jitter,
+		Seed:     seed,
+		Location: opts.Location,
+	})
+	if err != nil {
+		skip.Message = err.Error()
+		log(skip)
+		return nil
+	}
+	steps := c.breakSteps(shift, breaks)
+	next, reason := m.liveProgress(day.Day, steps)
+	if reason != "" {
+		skip.Message = reason
+		log(skip)
+		return nil
+	}
+	if next == len(steps) {
+		done := event(DaemonDone)
+		done.Message = "already recorded"
+		log(done)
+		return nil
+	}
+	if next == 0 && opts.Now().After(stepTime(date, steps[0].at).Add(opts.MaxDelay)) {
+		skip.Message = fmt.Sprintf("Too late to clock in at %s, use clock to back-fill the day", steps[0].at)
+		log(skip)
+		return nil
+	}
+
+	planned := event(DaemonPlanned)
+	if next > 0 {
+		planned = event(DaemonResumed)
+		planned.Endpoint = steps[next].endpoint
+		planned.At = steps[next].at
+	}
+	var windows []string
+	for _, s := range (DayResult{Shift: shift, Breaks: breaks}).Segments() {
+		windows = append(windows, s.Start+" - "+s.End)
+	}
+	planned.Message = strings.Join(windows, ", ")
+	log(planned)
+
+	for i := next; i < len(steps); i++ {
+		step := steps[i]
+		if at := stepTime(date, step.at); opts.Now().Before(at) {
+			waiting := event(DaemonWaiting)
+			waiting.Endpoint, waiting.At = step.endpoint, step.at
+			log(waiting)
+			if err := waitUntil(ctx, opts.Now, at); err != nil {
+				return err
+			}
+		}
+
+		for {
+			err := step.send(ctx, c.stepEvent(shift, step))
+			if err == nil {
+				sent := event(DaemonSent)
+				sent.Endpoint, sent.At = step.endpoint, step.at
+				log(sent)
+				break
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			failed := event(DaemonFailed)
+			failed.Endpoint, failed.At, failed.Err = step.endpoint, step.at, err
+			var authErr *AuthError
+			var validationErr *ValidationError
+			switch {
+			case errors.As(err, &authErr):
+				log(failed)
+				return err
+			case errors.As(err, &validationErr):
+				failed.Message = "giving up the day"
+				log(failed)
+				return nil
+			}
+
+			// The step may have gone through even if its response was lost
+			if shifts, stateErr := c.Shifts(ctx, m.EmployeeId, m.Year, m.Month); stateErr == nil {
+				m.Shifts = shifts
+				if recorded, _ := m.liveProgress(day.Day, steps); recorded > i {
+					failed.Message = "recorded despite the error"
+					log(failed)
+					break
+				}
+			}
+			failed.Message = fmt.Sprintf("retrying in %s", opts.RetryInterval)
+			log(failed)
+			if err := sleep(ctx, opts.RetryInterval); err != nil {
+				return err
+			}
+		}
+	}
+	log(event(DaemonDone))
+	return nil
+}
+
+// liveProgress returns the index of the first step of a day still to be
+// done. Shifts opened at the time of a step are taken as recorded by the
+// daemon, any other shift overlapping the day's steps skips the day with the
+// returned reason.
+func (m *Month) liveProgress(day int, steps []breakStep) (int, string) {
+	opened := map[string]int{}
+	for i := 0; i < len(steps); i += 2 {
+		opened[normalizeClock(steps[i].at)] = i
+	}
+	first, _ := parseClock(steps[0].at)
+	last, _ := parseClock(steps[len(steps)-1].at)
+
+	next := 0
+	for _, s := range m.Shifts {
+		if s.Day != day {
+			continue
+		}
+		if i, ok := opened[normalizeClock(s.ClockIn)]; ok {
+			done := i + 2
+			if s.ClockOut == "" {
+				done = i + 1
+			}
+			if done > next {
+				next = done
+			}
+			continue
+		}
+
+		in, err := parseClock(s.ClockIn)
+		if err != nil {
+			continue
+		}
+		out := 24 * 60
+		if s.ClockOut != "" {
+			if out, err = parseClock(s.ClockOut); err != nil {
+				continue
+			}
+		}
+		if in < last && out > first {
+			if s.ClockOut == "" {
+				return 0, fmt.Sprintf("Open shift since %s", s.ClockIn)
+			}
+			return 0, fmt.Sprintf("Period overlap: %s - %s", s.ClockIn, s.ClockOut)
+		}
+	}
+	return next, ""
+}
+
+// stepTime returns the time of a HH:MM step on date
+func stepTime(date time.Time, clock string) time.Time {
+	minutes, _ := parseClock(clock)
+	return time.Date(date.Year(), date.Month(), date.Day(), minutes/60, minutes%60, 0, 0, date.Location())
+}
+
+// waitUntil sleeps until t, checking the time at least every minute so that a
+// machine waking from suspend doesn't miss it
+func waitUntil(ctx context.Context, now func() time.Time, t time.Time) error {
+	for {
+		d := t.Sub(now())
+		if d <= 0 {
+			return nil
+		}
+		if d > time.Minute {
+			d = time.Minute
+		}
+		if err := sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+}
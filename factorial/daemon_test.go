@@ -0,0 +1,52 @@
+package factorial_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alejoar/factorialsucks/factorial"
+)
+
+// TestDaemonRestartWithJitter stops the daemon right after it clocks in and
+// starts it again, which has to pick up the open shift rather than skip the
+// day
+func TestDaemonRestartWithJitter(t *testing.T) {
+	s := newServer(t)
+	s.TimeZone = time.UTC
+	c := login(t, s)
+	opts := factorial.DaemonOptions{
+		Jitter: 10,
+		// The day's steps are all due, and still in time to be started
+		MaxDelay: 24 * time.Hour,
+		Now:      func() time.Time { return time.Date(2026, 10, 5, 23, 0, 0, 0, time.UTC) },
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.Daemon(ctx, opts, func(e factorial.DaemonEvent) {
+		if e.Action == factorial.DaemonSent {
+			cancel()
+		}
+	})
+	shifts := s.Shifts("2026-10-05")
+	if len(shifts) != 1 || shifts[0].ClockOut != "" {
+		t.Fatalf("shifts after the first run = %+v, want an open one", shifts)
+	}
+	opened := shifts[0].ClockIn
+
+	ctx, cancel = context.WithCancel(context.Background())
+	var actions []string
+	c.Daemon(ctx, opts, func(e factorial.DaemonEvent) {
+		actions = append(actions, e.Action)
+		if e.Action == factorial.DaemonSkipped || e.Action == factorial.DaemonDone {
+			cancel()
+		}
+	})
+	if len(actions) == 0 || actions[0] != factorial.DaemonResumed {
+		t.Errorf("restarted daemon actions = %q, want it to resume", actions)
+	}
+	shifts = s.Shifts("2026-10-05")
+	if len(shifts) != 2 || shifts[0].ClockIn != opened || shifts[1].ClockOut == "" {
+		t.Errorf("shifts after the restart = %+v, want the day closed from %s", shifts, opened)
+	}
+}
@@ -0,0 +1,186 @@
+package factorial
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AuthError is returned when signing in fails or the session is rejected
+type AuthError struct {
+	Message string
+}
+
+func (e *AuthError) Error() string {
+	return "authentication failed: " + e.Message
+}
+
+// PeriodNotFoundError is returned when Factorial has no attendance period for
+// the requested month
+type PeriodNotFoundError struct {
+	Year  int
+	Month int
+}
+
+func (e *PeriodNotFoundError) Error() string {
+	return fmt.Sprintf("Could not find the specified year/month in the available periods (%d/%d)", e.Month, e.Year)
+}
+
+// EmployeeMismatchError is returned when restoring a backup taken from
+// another employee's account
+type EmployeeMismatchError struct {
+	Backup   int
+	Employee int
+}
+
+func (e *EmployeeMismatchError) Error() string {
+	return fmt.Sprintf("the backup belongs to employee %d, not to employee %d", e.Backup, e.Employee)
+}
+
+// ValidationError is returned when Factorial rejects a request, Message holds
+// the reason given by the server
+type ValidationError struct {
+	Method  string
+	Path    string
+	Status  int
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s %s rejected: %s", e.Method, e.Path, e.Message)
+}
+
+// TransportError is returned when a request couldn't be sent or its response
+// couldn't be read, e.g. on DNS failures or timeouts
+type TransportError struct {
+	Method string
+	Path   string
+	Err    error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Method, e.Path, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// StatusError is returned when Factorial answers with an unexpected status
+type StatusError struct {
+	Method string
+	Path   string
+	Status int
+	Body   string
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("%s %s: unexpected status %d", e.Method, e.Path, e.Status)
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// DayError is the failure of a single day of a run
+type DayError struct {
+	Date time.Time
+	Err  error
+}
+
+func (e *DayError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Date.Format("02 Jan 2006"), e.Err)
+}
+
+func (e *DayError) Unwrap() error {
+	return e.Err
+}
+
+// RunError is returned by ClockIn and ResetMonth when some days failed, the
+// rest of the days are still processed
+type RunError struct {
+	Errors []*DayError
+}
+
+func (e *RunError) Error() string {
+	lines := []string{fmt.Sprintf("%d day(s) failed:", len(e.Errors))}
+	for _, err := range e.Errors {
+		lines = append(lines, "  "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns the first failure so errors.As can find its cause
+func (e *RunError) Unwrap() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e.Errors[0]
+}
+
+// checkStatus turns an unexpected response into a typed error
+func checkStatus(req *http.Request, resp *http.Response, body string, status int) error {
+	if resp.StatusCode == status {
+		return nil
+	}
+	path := req.URL.Path
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &AuthError{Message: fmt.Sprintf("%s %s: %s", req.Method, path, serverMessage(body, resp.Status))}
+	case http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusConflict:
+		return &ValidationError{
+			Method:  req.Method,
+			Path:    path,
+			Status:  resp.StatusCode,
+			Message: serverMessage(body, resp.Status),
+		}
+	}
+	return &StatusError{Method: req.Method, Path: path, Status: resp.StatusCode, Body: truncate(strings.TrimSpace(body), 200)}
+}
+
+// serverMessage extracts the error messages from a JSON error body, falling
+// back to the raw body
+func serverMessage(body, fallback string) string {
+	var data interface{}
+	if err := json.Unmarshal([]byte(body), &data); err == nil {
+		var messages []string
+		collectMessages(data, &messages)
+		if len(messages) > 0 {
+			return strings.Join(messages, "; ")
+		}
+	}
+	if body = strings.TrimSpace(body); body != "" {
+		return truncate(body, 200)
+	}
+	return fallback
+}
+
+func collectMessages(data interface{}, messages *[]string) {
+	switch v := data.(type) {
+	case string:
+		*messages = append(*messages, v)
+	case []interface{}:
+		for _, item := range v {
+			collectMessages(item, messages)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			collectMessages(v[k], messages)
+		}
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
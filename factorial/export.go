@@ -0,0 +1,110 @@
+package factorial
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteCSV writes shifts as CSV with a header: date, clock in, clock out,
+// minutes, location type and source. Open shifts have no clock out.
+func WriteCSV(w io.Writer, shifts []BackupShift) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "clock_in", "clock_out", "minutes", "location_type", "source"})
+	for _, s := range shifts {
+		cw.Write([]string{
+			s.Date,
+			s.ClockIn,
+			s.ClockOut,
+			strconv.Itoa(shiftMinutes([]Shift{s.Shift})),
+			s.LocationType,
+			s.Source,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteICS writes the closed shifts as iCalendar events in floating local
+// time, stamped with now
+func WriteICS(w io.Writer, shifts []BackupShift, now time.Time) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//factorialsucks//shifts//EN",
+		"CALSCALE:GREGORIAN",
+	}
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, s := range shifts {
+		if s.ClockOut == "" {
+			continue
+		}
+		start, err := icsTime(s.Date, s.ClockIn)
+		if err != nil {
+			return err
+		}
+		end, err := icsTime(s.Date, s.ClockOut)
+		if err != nil {
+			return err
+		}
+		uid := fmt.Sprintf("%s-%s@factorialsucks", s.Date, strings.Replace(s.ClockIn, ":", "", 1))
+		if s.Id != 0 {
+			uid = fmt.Sprintf("shift-%d@factorialsucks", s.Id)
+		}
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+uid,
+			"DTSTAMP:"+stamp,
+			"DTSTART:"+start,
+			"DTEND:"+end,
+			"SUMMARY:Work",
+			"CATEGORIES:Work",
+			"DESCRIPTION:"+icsEscape(fmt.Sprintf("Location: %s\nSource: %s", s.LocationType, s.Source)),
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, icsFold(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// icsTime formats a date and a HH:MM time as a floating iCalendar date-time
+func icsTime(date, clock string) (string, error) {
+	t, err := time.Parse("2006-01-02 15:04", date+" "+clock)
+	if err != nil {
+		return "", fmt.Errorf("invalid shift time %s %s", date, clock)
+	}
+	return t.Format("20060102T150405"), nil
+}
+
+// icsEscape escapes a text value
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// icsFold splits lines longer than 75 octets, continuing them with a space
+func icsFold(line string) string {
+	var b strings.Builder
+	// Continuation lines start with the space, leaving 74 octets
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// Don't split UTF-8 sequences
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	b.WriteString(line)
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package factorial
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// exportShifts covers escaping, folding and open shifts
+var exportShifts = []BackupShift{
+	{Date: "2026-10-05", Shift: Shift{Id: 41, ClockIn: "09:00", ClockOut: "13:00", LocationType: "work_from_home", Source: "manual"}},
+	{Date: "2026-10-05", Shift: Shift{ClockIn: "14:00", ClockOut: "18:30", LocationType: "office", Source: `desk,top; back\slash`}},
+	{Date: "2026-10-06", Shift: Shift{Id: 43, ClockIn: "09:00", ClockOut: "17:00", Minutes: 450, LocationType: "business_trip", Source: "approved by the manager of the team in Logroño, España"}},
+	{Date: "2026-10-07", Shift: Shift{Id: 44, ClockIn: "09:00", LocationType: "office", Source: "desktop"}},
+}
+
+// golden compares got with the file in testdata, rewriting it with -update
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteCSV(&b, exportShifts); err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "shifts.csv", b.Bytes())
+}
+
+func TestWriteICS(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteICS(&b, exportShifts, time.Date(2026, 10, 18, 10, 30, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "shifts.ics", b.Bytes())
+
+	for _, line := range strings.SplitAfter(b.String(), "\r\n") {
+		if len(line) > 75+len("\r\n") {
+			t.Errorf("line of %d octets: %q", len(line)-2, line)
+		}
+	}
+	// The export reads back as the days' shifts, the gaps being breaks
+	shifts, err := ReadICS(&b, ICSOptions{Location: time.UTC, Range: MonthRange(2026, 10)})
+	if err != nil {
+		t.Fatalf("ReadICS: %v", err)
+	}
+	var got []string
+	for _, s := range shifts {
+		day := s.Date.Format("2006-01-02") + " " + s.ClockIn + " - " + s.ClockOut
+		for _, br := range s.Breaks {
+			day += " break " + br.Start + " - " + br.End
+		}
+		got = append(got, day)
+	}
+	want := []string{"2026-10-05 09:00 - 18:30 break 13:00 - 14:00", "2026-10-06 09:00 - 17:00"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("read back %q, want %q", got, want)
+	}
+}
+
+func TestICSFold(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"short", "SUMMARY:Work", "SUMMARY:Work"},
+		{"75 octets", strings.Repeat("a", 75), strings.Repeat("a", 75)},
+		{"76 octets", strings.Repeat("a", 76), strings.Repeat("a", 75) + "\r\n a"},
+		{"two folds", strings.Repeat("a", 75+74+1), strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n a"},
+		// ñ takes two octets, the 75th would split it
+		{"multibyte at the fold", strings.Repeat("a", 74) + "ñb", strings.Repeat("a", 74) + "\r\n ñb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := icsFold(tt.line); got != tt.want {
+				t.Errorf("icsFold = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestICSEscape(t *testing.T) {
+	in := "a,b;c\\d\ne"
+	want := `a\,b\;c\\d\ne`
+	if got := icsEscape(in); got != want {
+		t.Errorf("icsEscape(%q) = %q, want %q", in, got, want)
+	}
+	if got := icsUnescape(want); got != in {
+		t.Errorf("icsUnescape(%q) = %q, want %q", want, got, in)
+	}
+}
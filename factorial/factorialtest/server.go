@@ -0,0 +1,643 @@
+// Package factorialtest provides an in-process fake of the FactorialHR API
+// for end-to-end tests of the factorial package.
+package factorialtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alejoar/factorialsucks/factorial"
+)
+
+const (
+	sessionCookie = "_factorial_session"
+	breakPrefix   = "/api/2025-10-01/resources/attendance/shifts/"
+)
+
+// Minutes expected on weekdays unless set with SetExpectedMinutes
+const (
+	RegularShiftMinutes = 495 // 8:15 hours
+	FridayShiftMinutes  = 420 // 7:00 hours
+)
+
+// Server is a stateful fake of the FactorialHR endpoints used by the
+// factorial package. Every month is available, weekdays expect
+// RegularShiftMinutes (FridayShiftMinutes on Fridays) and weekends are not
+// laborable.
+type Server struct {
+	*httptest.Server
+	Email      string
+	Password   string
+	EmployeeId int
+	// TimeZone is the employee's, the clock in endpoints reject timestamps
+	// with another offset. Defaults to time.Local, like factorial.Client.
+	TimeZone *time.Location
+
+	mu       sync.Mutex
+	csrf     string
+	sessions map[string]bool
+	days     map[string]*day
+	shifts   []record
+	nextId   int64
+	onBreak  *factorial.Shift
+	requests []string
+	faults   []*fault
+}
+
+// fault makes requests matching method and path fail
+type fault struct {
+	method string
+	path   string
+	times  int
+	status int
+	body   string
+}
+
+type day struct {
+	holiday   bool
+	leaveName string
+	leaves    []factorial.CalendarLeave
+	minutes   float64
+	hasMinute bool
+}
+
+// record is a stored shift with the date it belongs to
+type record struct {
+	date  string
+	shift factorial.Shift
+}
+
+// NewServer starts a fake server accepting the given credentials
+func NewServer(email, password string) *Server {
+	s := &Server{
+		Email:      email,
+		Password:   password,
+		EmployeeId: 1234,
+		TimeZone:   time.Local,
+		csrf:       token(),
+		sessions:   map[string]bool{},
+		days:       map[string]*day{},
+		nextId:     1,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/sign_in", s.signIn)
+	mux.HandleFunc("/", s.home)
+	mux.HandleFunc("/attendance/periods", s.auth(s.periods))
+	mux.HandleFunc("/attendance/calendar", s.auth(s.calendar))
+	mux.HandleFunc("/attendance/shifts", s.auth(s.shiftsHandler))
+	mux.HandleFunc("/attendance/shifts/", s.auth(s.deleteShift))
+	mux.HandleFunc(breakPrefix, s.auth(s.breakHandler))
+	s.Server = httptest.NewServer(s.log(mux))
+	return s
+}
+
+// NewClient returns a factorial.Client pointed at the server, in the
+// employee's time zone
+func (s *Server) NewClient() *factorial.Client {
+	c := factorial.NewClient()
+	c.BaseUrl = s.URL
+	c.TimeZone = s.TimeZone
+	return c
+}
+
+// SetHoliday marks a date (YYYY-MM-DD) as a non-laborable holiday
+func (s *Server) SetHoliday(date string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.day(date).holiday = true
+}
+
+// SetLeave records a leave on a date (YYYY-MM-DD)
+func (s *Server) SetLeave(date, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.day(date).leaveName = name
+}
+
+// SetPartialLeave records a leave taking part of a date (YYYY-MM-DD). The
+// date expects the minutes left, half of them for a half day leave without
+// minutes.
+func (s *Server) SetPartialLeave(date string, leave factorial.CalendarLeave) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	minutes := s.expectedMinutes(date)
+	taken := leave.Minutes
+	if taken == 0 {
+		taken = minutes / 2
+	}
+	d := s.day(date)
+	d.leaves = append(d.leaves, leave)
+	d.minutes = minutes - taken
+	d.hasMinute = true
+}
+
+// SetExpectedMinutes overrides the minutes expected on a date (YYYY-MM-DD)
+func (s *Server) SetExpectedMinutes(date string, minutes float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.day(date)
+	d.minutes = minutes
+	d.hasMinute = true
+}
+
+// AddShift stores a shift as if it had been recorded by hand
+func (s *Server) AddShift(date, clockIn, clockOut string) factorial.Shift {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addShift(date, clockIn, clockOut, "work_from_home", "manual")
+}
+
+// Shifts returns the stored shifts of a date (YYYY-MM-DD), or every shift
+// when date is empty, sorted by date and clock in
+func (s *Server) Shifts(date string) []factorial.Shift {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := append([]record(nil), s.shifts...)
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].date != records[j].date {
+			return records[i].date < records[j].date
+		}
+		return records[i].shift.ClockIn < records[j].shift.ClockIn
+	})
+	var shifts []factorial.Shift
+	for _, r := range records {
+		if date == "" || r.date == date {
+			shifts = append(shifts, r.shift)
+		}
+	}
+	return shifts
+}
+
+// Requests returns the "METHOD /path" of every request received so far
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// ExpireSessions signs out every client, as Factorial does when sessions
+// expire
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = map[string]bool{}
+}
+
+// Fail makes the next times requests with the given method and path fail
+// with status and body, without changing any state. A negative times fails
+// every matching request. 429 responses ask to retry after a second.
+func (s *Server) Fail(method, path string, times, status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault{method: method, path: path, times: times, status: status, body: body})
+}
+
+func (s *Server) log(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		f := s.fault(r)
+		s.mu.Unlock()
+		if f != nil {
+			if f.status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "1")
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(f.status)
+			fmt.Fprint(w, f.body)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) fault(r *http.Request) *fault {
+	for _, f := range s.faults {
+		if f.times != 0 && f.method == r.Method && f.path == r.URL.Path {
+			if f.times > 0 {
+				f.times--
+			}
+			return f
+		}
+	}
+	return nil
+}
+
+func (s *Server) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(sessionCookie)
+		s.mu.Lock()
+		ok := err == nil && s.sessions[cookie.Value]
+		s.mu.Unlock()
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "You need to sign in or sign up before continuing."})
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		next(w, r)
+	}
+}
+
+func (s *Server) home(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	fmt.Fprint(w, "<html><body>Factorial</body></html>")
+}
+
+func (s *Server) signIn(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case "GET":
+		fmt.Fprintf(w, "<html><head><meta name=\"csrf-token\" content=\"%s\" /></head><body></body></html>", s.csrf)
+	case "POST":
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.PostForm.Get("authenticity_token") != s.csrf {
+			http.Error(w, "Invalid authenticity token", http.StatusUnprocessableEntity)
+			return
+		}
+		if r.PostForm.Get("user[email]") != s.Email || r.PostForm.Get("user[password]") != s.Password {
+			fmt.Fprint(w, "<html><body><div class=\"flash flash--wrong\">Invalid email or password.</div></body></html>")
+			return
+		}
+		session := token()
+		s.sessions[session] = true
+		http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: session, Path: "/", HttpOnly: true})
+		http.Redirect(w, r, "/", http.StatusFound)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) periods(w http.ResponseWriter, r *http.Request) {
+	year, month, ok := yearMonth(r)
+	if !ok {
+		writeJSON(w, http.StatusOK, []factorial.Period{})
+		return
+	}
+	dates := monthDates(year, month)
+	p := factorial.Period{
+		Id:         year*100 + month,
+		EmployeeId: s.EmployeeId,
+		Year:       year,
+		Month:      month,
+		StartOn:    dates[0],
+		EndOn:      dates[len(dates)-1],
+		State:      "pending",
+	}
+	for _, date := range dates {
+		expected := s.expectedMinutes(date)
+		tracked := 0
+		for _, rec := range s.shifts {
+			if rec.date == date {
+				tracked += int(rec.shift.Minutes)
+			}
+		}
+		p.EstimatedRegularMinutesDistribution = append(p.EstimatedRegularMinutesDistribution, expected)
+		p.EstimatedMinutesDistribution = append(p.EstimatedMinutesDistribution, int(expected))
+		p.TrackedMinutesDistribution = append(p.TrackedMinutesDistribution, tracked)
+		p.BalanceMinutesDistribution = append(p.BalanceMinutesDistribution, tracked-int(expected))
+		p.EstimatedMinutes += int(expected)
+		p.EstimatedRegularMinutes += int(expected)
+		p.TrackedMinutes += tracked
+		p.WorkedMinutes += tracked
+	}
+	p.BalanceMinutes = strconv.Itoa(p.WorkedMinutes - p.EstimatedMinutes)
+	p.Permissions.Read = true
+	p.Permissions.Edit = true
+	p.Permissions.Delete = true
+	writeJSON(w, http.StatusOK, []factorial.Period{p})
+}
+
+func (s *Server) calendar(w http.ResponseWriter, r *http.Request) {
+	year, month, ok := yearMonth(r)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "year and month are required"})
+		return
+	}
+	var days []factorial.CalendarDay
+	for i, date := range monthDates(year, month) {
+		t, _ := time.Parse("2006-01-02", date)
+		next := t.AddDate(0, 0, 1).Format("2006-01-02")
+		d := s.lookup(date)
+		day := factorial.CalendarDay{
+			Id:               date,
+			Day:              i + 1,
+			Date:             date,
+			DayBeforeHoliday: s.lookup(next).holiday,
+			IsLaborable:      s.laborable(date),
+			IsLeave:          d.leaveName != "" || len(d.leaves) > 0,
+			LeaveName:        d.leaveName,
+			MinutesLeft:      s.expectedMinutes(date),
+			Leaves:           append([]factorial.CalendarLeave(nil), d.leaves...),
+		}
+		if d.leaveName != "" {
+			day.Leaves = append(day.Leaves, factorial.CalendarLeave{Name: d.leaveName})
+		} else if len(d.leaves) > 0 {
+			day.LeaveName = d.leaves[0].Name
+		}
+		days = append(days, day)
+	}
+	writeJSON(w, http.StatusOK, days)
+}
+
+func (s *Server) shiftsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		year, month, ok := yearMonth(r)
+		prefix := fmt.Sprintf("%04d-%02d-", year, month)
+		shifts := []factorial.Shift{}
+		for _, rec := range s.shifts {
+			if !ok || strings.HasPrefix(rec.date, prefix) {
+				shifts = append(shifts, rec.shift)
+			}
+		}
+		writeJSON(w, http.StatusOK, shifts)
+	case "POST":
+		var shift factorial.NewShift
+		if err := json.NewDecoder(r.Body).Decode(&shift); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		date := shift.Date
+		if date == "" {
+			date = shift.ReferenceDate
+		}
+		msg := s.validate(date, shift.ClockIn, shift.ClockOut, 0)
+		if msg == "" && shift.ClockIn == shift.ClockOut {
+			msg = "Clock out must be after clock in"
+		}
+		if msg != "" {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string][]string{"base": {msg}})
+			return
+		}
+		writeJSON(w, http.StatusCreated, s.addShift(date, shift.ClockIn, shift.ClockOut, shift.LocationType, shift.Source))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) deleteShift(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "DELETE" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/attendance/shifts/"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	for i, rec := range s.shifts {
+		if rec.shift.Id == id {
+			s.shifts = append(s.shifts[:i], s.shifts[i+1:]...)
+			if s.onBreak != nil && s.onBreak.Id == id {
+				s.onBreak = nil
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// breakHandler implements the live clock in endpoints. Clocking in stores an
+// open shift (without clock out), a break closes it and break_end opens a new
+// one, so a day with one break ends up as two shifts.
+func (s *Server) breakHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var event factorial.BreakShift
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	// Timestamps without an offset are in the employee's time zone, the
+	// ones with an offset must carry the one in effect there
+	zone := s.TimeZone
+	if zone == nil {
+		zone = time.Local
+	}
+	now, err := time.Parse(time.RFC3339, event.Now)
+	if err == nil {
+		_, offset := now.Zone()
+		if _, want := now.In(zone).Zone(); offset != want {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": fmt.Sprintf(
+				"now %s has offset %s, %s is at %s then", event.Now, now.Format("-07:00"), zone, now.In(zone).Format("-07:00"))})
+			return
+		}
+	} else {
+		now, err = time.ParseInLocation("2006-01-02T15:04", event.Now, zone)
+	}
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "invalid now: " + event.Now})
+		return
+	}
+	now = now.In(zone)
+	date, clock := now.Format("2006-01-02"), now.Format("15:04")
+
+	fail := func(msg string) {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": msg})
+	}
+	open := s.openShift()
+	switch action := strings.TrimPrefix(r.URL.Path, breakPrefix); action {
+	case "clock_in":
+		if open != nil || s.onBreak != nil {
+			fail("There is already an open shift")
+			return
+		}
+		if msg := s.validate(date, clock, clock, 0); msg != "" {
+			fail(msg)
+			return
+		}
+		s.addShift(date, clock, "", event.LocationType, "desktop")
+	case "break_start", "clock_out":
+		if open == nil || open.date != date {
+			fail("There is no open shift")
+			return
+		}
+		if msg := s.validate(date, open.shift.ClockIn, clock, open.shift.Id); msg != "" {
+			fail(msg)
+			return
+		}
+		s.close(open, clock)
+		if action == "break_start" {
+			shift := open.shift
+			s.onBreak = &shift
+		}
+	case "break_end":
+		if s.onBreak == nil || s.onBreak.ClockOut >= clock || date != recordDate(*s.onBreak) {
+			fail("There is no open break")
+			return
+		}
+		if msg := s.validate(date, clock, clock, 0); msg != "" {
+			fail(msg)
+			return
+		}
+		location := s.onBreak.LocationType
+		if event.LocationType != "" {
+			location = event.LocationType
+		}
+		s.addShift(date, clock, "", location, "desktop")
+		s.onBreak = nil
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// openShift returns the shift opened through clock in, if any
+func (s *Server) openShift() *record {
+	for i := range s.shifts {
+		if s.shifts[i].shift.ClockOut == "" {
+			return &s.shifts[i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) close(rec *record, clockOut string) {
+	in, _ := time.Parse("15:04", rec.shift.ClockIn)
+	out, _ := time.Parse("15:04", clockOut)
+	rec.shift.ClockOut = clockOut
+	rec.shift.Minutes = int64(out.Sub(in).Minutes())
+}
+
+// validate returns why a shift can't be recorded, or an empty string. The
+// shift with id ignore is left out of the overlap check, and open shifts
+// count as lasting until the end of the day.
+func (s *Server) validate(date, clockIn, clockOut string, ignore int64) string {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return "Date is invalid"
+	}
+	in, errIn := time.Parse("15:04", clockIn)
+	out, errOut := time.Parse("15:04", clockOut)
+	if errIn != nil || errOut != nil {
+		return "Clock in and clock out must be HH:MM"
+	}
+	if out.Before(in) || (out.Equal(in) && clockIn != clockOut) {
+		return "Clock out must be after clock in"
+	}
+	if clockIn == clockOut {
+		// An instant, e.g. the clock in of an open shift
+		clockOut = clockIn + ":00"
+	}
+	for _, rec := range s.shifts {
+		end := rec.shift.ClockOut
+		if end == "" {
+			end = "24:00"
+		}
+		if rec.shift.Id != ignore && rec.date == date && clockIn < end && rec.shift.ClockIn < clockOut {
+			return fmt.Sprintf("Shift overlaps with %s - %s", rec.shift.ClockIn, rec.shift.ClockOut)
+		}
+	}
+	return ""
+}
+
+// addShift stores a shift, an empty clockOut stores it open
+func (s *Server) addShift(date, clockIn, clockOut, locationType, source string) factorial.Shift {
+	t, _ := time.Parse("2006-01-02", date)
+	rec := record{date: date, shift: factorial.Shift{
+		Id:           s.nextId,
+		PeriodId:     int64(t.Year()*100 + int(t.Month())),
+		Day:          t.Day(),
+		ClockIn:      clockIn,
+		LocationType: locationType,
+		Source:       source,
+	}}
+	if clockOut != "" {
+		s.close(&rec, clockOut)
+	}
+	s.nextId++
+	s.shifts = append(s.shifts, rec)
+	return rec.shift
+}
+
+func recordDate(shift factorial.Shift) string {
+	return fmt.Sprintf("%04d-%02d-%02d", shift.PeriodId/100, shift.PeriodId%100, shift.Day)
+}
+
+func (s *Server) day(date string) *day {
+	d, ok := s.days[date]
+	if !ok {
+		d = &day{}
+		s.days[date] = d
+	}
+	return d
+}
+
+func (s *Server) lookup(date string) day {
+	if d, ok := s.days[date]; ok {
+		return *d
+	}
+	return day{}
+}
+
+func (s *Server) laborable(date string) bool {
+	t, _ := time.Parse("2006-01-02", date)
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	return !s.lookup(date).holiday
+}
+
+func (s *Server) expectedMinutes(date string) float64 {
+	d := s.lookup(date)
+	if d.hasMinute {
+		return d.minutes
+	}
+	if !s.laborable(date) || d.leaveName != "" {
+		return 0
+	}
+	t, _ := time.Parse("2006-01-02", date)
+	if t.Weekday() == time.Friday {
+		return FridayShiftMinutes
+	}
+	return RegularShiftMinutes
+}
+
+func yearMonth(r *http.Request) (int, int, bool) {
+	year, errYear := strconv.Atoi(r.URL.Query().Get("year"))
+	month, errMonth := strconv.Atoi(r.URL.Query().Get("month"))
+	if errYear != nil || errMonth != nil || month < 1 || month > 12 {
+		return 0, 0, false
+	}
+	return year, month, true
+}
+
+func monthDates(year, month int) []string {
+	var dates []string
+	for t := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC); int(t.Month()) == month; t = t.AddDate(0, 0, 1) {
+		dates = append(dates, t.Format("2006-01-02"))
+	}
+	return dates
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func token() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
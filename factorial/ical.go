@@ -0,0 +1,293 @@
+package factorial
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ICSOptions selects the events of an iCalendar file that are worked time
+type ICSOptions struct {
+	// Categories and Summaries match events case insensitively, by one of
+	// their categories or their whole summary. When both are empty events
+	// categorized or titled Work match.
+	Categories []string
+	Summaries  []string
+	// Location is the time zone of the shifts, events in other time zones
+	// are converted to it. Defaults to time.Local.
+	Location *time.Location
+	// Range limits the shifts read to its days, recurring events are only
+	// expanded up to its last day. Defaults to the current month.
+	Range DateRange
+}
+
+// icsEvent is a timed event of an iCalendar file. Times are in the event's
+// time zone.
+type icsEvent struct {
+	uid        string
+	summary    string
+	categories []string
+	start, end time.Time
+	cancelled  bool
+	// rrule, rdates, exdates and exdays (YYYY-MM-DD) describe the
+	// occurrences of a recurring event. recurrenceId is set on events
+	// replacing one of them, see occurrenceKey.
+	rrule        string
+	rdates       []time.Time
+	exdates      []time.Time
+	exdays       map[string]bool
+	recurrenceId string
+}
+
+// ReadICS reads planned shifts from the events of an iCalendar file. The
+// matching events of each day become the worked segments of a shift, the gaps
+// between them its breaks. All day and cancelled events are ignored, and so
+// are the events outside opts.Range. Recurring events are expanded for daily
+// and weekly rules, other rules are an error.
+func ReadICS(r io.Reader, opts ICSOptions) ([]PlannedShift, error) {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	if opts.Range.From.IsZero() {
+		now := time.Now().In(opts.Location)
+		opts.Range = MonthRange(now.Year(), int(now.Month()))
+	}
+	to := opts.Range.To
+	horizon := time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, opts.Location)
+	if len(opts.Categories) == 0 && len(opts.Summaries) == 0 {
+		opts.Categories, opts.Summaries = []string{"Work"}, []string{"Work"}
+	}
+	events, err := readEvents(r, opts.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	// Occurrences moved or cancelled are events of their own
+	overridden := map[string]bool{}
+	for _, e := range events {
+		if e.recurrenceId != "" {
+			overridden[e.uid+e.recurrenceId] = true
+		}
+	}
+
+	days := map[string][]icsEvent{}
+	for _, e := range events {
+		if e.cancelled || !opts.matches(e) {
+			continue
+		}
+		starts, err := e.occurrences(horizon, overridden)
+		if err != nil {
+			return nil, err
+		}
+		duration := e.end.Sub(e.start)
+		for _, start := range starts {
+			o := e
+			o.start, o.end = start.In(opts.Location), start.Add(duration).In(opts.Location)
+			if !opts.Range.Contains(o.start) {
+				continue
+			}
+			if o.end.Format("2006-01-02") != o.start.Format("2006-01-02") && o.end.Format("15:04") != "00:00" {
+				return nil, fmt.Errorf("event %q on %s crosses midnight", o.summary, o.start.Format("2006-01-02 15:04"))
+			}
+			key := o.start.Format("2006-01-02")
+			days[key] = append(days[key], o)
+		}
+	}
+
+	var shifts []PlannedShift
+	for key, events := range days {
+		sort.Slice(events, func(i, j int) bool { return events[i].start.Before(events[j].start) })
+		date, _ := time.Parse("2006-01-02", key)
+		s := PlannedShift{Date: date, ClockIn: events[0].start.Format("15:04")}
+		end := events[0].end
+		for _, e := range events[1:] {
+			if e.start.After(end) {
+				s.Breaks = append(s.Breaks, Segment{Start: end.Format("15:04"), End: e.start.Format("15:04")})
+			}
+			if e.end.After(end) {
+				end = e.end
+			}
+		}
+		s.ClockOut = end.Format("15:04")
+		if s.ClockOut == "00:00" {
+			s.ClockOut = "23:59"
+		}
+		if err := checkTimes(s.ClockIn, s.ClockOut, s.Breaks); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		shifts = append(shifts, s)
+	}
+	sort.Slice(shifts, func(i, j int) bool { return shifts[i].Date.Before(shifts[j].Date) })
+	return shifts, nil
+}
+
+func (opts ICSOptions) matches(e icsEvent) bool {
+	for _, c := range e.categories {
+		for _, want := range opts.Categories {
+			if strings.EqualFold(c, want) {
+				return true
+			}
+		}
+	}
+	for _, want := range opts.Summaries {
+		if strings.EqualFold(e.summary, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// readEvents parses the timed VEVENTs of an iCalendar file
+func readEvents(r io.Reader, loc *time.Location) ([]icsEvent, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []icsEvent
+	var event *icsEvent
+	allDay := false
+	for _, line := range lines {
+		name, params, value := parseContentLine(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event, allDay = &icsEvent{exdays: map[string]bool{}}, false
+		case event == nil:
+			continue
+		case name == "END" && value == "VEVENT":
+			if !allDay && !event.start.IsZero() && !event.end.IsZero() {
+				events = append(events, *event)
+			}
+			event = nil
+		case name == "UID":
+			event.uid = value
+		case name == "RRULE":
+			event.rrule = value
+		case name == "RECURRENCE-ID":
+			if params["VALUE"] == "DATE" {
+				d, err := time.Parse("20060102", value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid date %q", value)
+				}
+				event.recurrenceId = "|" + d.Format("2006-01-02")
+				continue
+			}
+			t, err := parseICSTime(value, params["TZID"], loc)
+			if err != nil {
+				return nil, err
+			}
+			event.recurrenceId = occurrenceKey("", t)
+		case name == "EXDATE" || name == "RDATE":
+			for _, v := range strings.Split(value, ",") {
+				if params["VALUE"] == "DATE" && name == "EXDATE" {
+					d, err := time.Parse("20060102", v)
+					if err != nil {
+						return nil, fmt.Errorf("invalid date %q", v)
+					}
+					event.exdays[d.Format("2006-01-02")] = true
+					continue
+				}
+				if params["VALUE"] != "" && params["VALUE"] != "DATE-TIME" {
+					return nil, fmt.Errorf("%s;VALUE=%s is not supported", name, params["VALUE"])
+				}
+				t, err := parseICSTime(v, params["TZID"], loc)
+				if err != nil {
+					return nil, err
+				}
+				if name == "EXDATE" {
+					event.exdates = append(event.exdates, t)
+				} else {
+					event.rdates = append(event.rdates, t)
+				}
+			}
+		case name == "SUMMARY":
+			event.summary = icsUnescape(value)
+		case name == "CATEGORIES":
+			for _, c := range strings.Split(value, ",") {
+				event.categories = append(event.categories, strings.TrimSpace(icsUnescape(c)))
+			}
+		case name == "STATUS":
+			event.cancelled = strings.EqualFold(value, "CANCELLED")
+		case name == "DTSTART" || name == "DTEND":
+			if params["VALUE"] == "DATE" {
+				allDay = true
+				continue
+			}
+			t, err := parseICSTime(value, params["TZID"], loc)
+			if err != nil {
+				return nil, err
+			}
+			if name == "DTSTART" {
+				event.start = t
+			} else {
+				event.end = t
+			}
+		}
+	}
+	return events, nil
+}
+
+// unfoldLines reads the content lines of an iCalendar file, joining the
+// lines folded with a leading space or tab
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseContentLine splits NAME;PARAM=VALUE:VALUE
+func parseContentLine(line string) (string, map[string]string, string) {
+	head, value := line, ""
+	if i := strings.Index(line, ":"); i >= 0 {
+		head, value = line[:i], line[i+1:]
+	}
+	parts := strings.Split(head, ";")
+	params := map[string]string{}
+	for _, p := range parts[1:] {
+		if kv := strings.SplitN(p, "=", 2); len(kv) == 2 {
+			params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, value
+}
+
+// parseICSTime parses an iCalendar date-time in UTC, in the time zone tzid or
+// floating, which is taken to be in loc. It's returned in its own time zone.
+func parseICSTime(value, tzid string, loc *time.Location) (time.Time, error) {
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return t, fmt.Errorf("invalid date-time %q", value)
+		}
+		return t, nil
+	}
+	in := loc
+	if tzid != "" {
+		var err error
+		if in, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %q", tzid)
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, in)
+	if err != nil {
+		return t, fmt.Errorf("invalid date-time %q", value)
+	}
+	return t, nil
+}
+
+// icsUnescape undoes icsEscape
+func icsUnescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
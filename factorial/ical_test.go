@@ -0,0 +1,96 @@
+package factorial
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// icsFile wraps events in a calendar
+func icsFile(events ...string) string {
+	return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.Join(events, "") + "END:VCALENDAR\r\n"
+}
+
+func TestReadICSRecurring(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skip("no time zone data:", err)
+	}
+	october := MonthRange(2026, 10)
+
+	tests := []struct {
+		name  string
+		event string
+		r     DateRange
+		want  []string
+	}{
+		{
+			name: "weekly by day with count",
+			event: "RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4\r\n" +
+				"DTSTART;TZID=Europe/Madrid:20261005T090000\r\nDTEND;TZID=Europe/Madrid:20261005T130000\r\n",
+			want: []string{"2026-10-05 09:00-13:00", "2026-10-07 09:00-13:00", "2026-10-12 09:00-13:00", "2026-10-14 09:00-13:00"},
+		},
+		{
+			name: "weekly without end stops at the end of the range",
+			event: "RRULE:FREQ=WEEKLY;INTERVAL=2\r\n" +
+				"DTSTART;TZID=Europe/Madrid:20261002T080000\r\nDTEND;TZID=Europe/Madrid:20261002T150000\r\n",
+			want: []string{"2026-10-02 08:00-15:00", "2026-10-16 08:00-15:00", "2026-10-30 08:00-15:00"},
+		},
+		{
+			name: "daily until with exdates across the change to winter time",
+			event: "RRULE:FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;UNTIL=20261027T235959Z\r\n" +
+				"DTSTART;TZID=Europe/Madrid:20261022T090000\r\nDTEND;TZID=Europe/Madrid:20261022T100000\r\n" +
+				"EXDATE;TZID=Europe/Madrid:20261023T090000\r\nEXDATE;VALUE=DATE:20261027\r\n",
+			want: []string{"2026-10-22 09:00-10:00", "2026-10-26 09:00-10:00"},
+		},
+		{
+			name: "moved occurrence",
+			event: "RRULE:FREQ=DAILY;COUNT=2\r\n" +
+				"DTSTART;TZID=Europe/Madrid:20261005T090000\r\nDTEND;TZID=Europe/Madrid:20261005T100000\r\n" +
+				"END:VEVENT\r\nBEGIN:VEVENT\r\nUID:a\r\nSUMMARY:Work\r\n" +
+				"RECURRENCE-ID;TZID=Europe/Madrid:20261006T090000\r\n" +
+				"DTSTART;TZID=Europe/Madrid:20261006T110000\r\nDTEND;TZID=Europe/Madrid:20261006T120000\r\n",
+			want: []string{"2026-10-05 09:00-10:00", "2026-10-06 11:00-12:00"},
+		},
+		{
+			name: "weekly started years ago only in the range",
+			event: "RRULE:FREQ=WEEKLY;BYDAY=MO\r\n" +
+				"DTSTART;TZID=Europe/Madrid:20200106T090000\r\nDTEND;TZID=Europe/Madrid:20200106T170000\r\n",
+			r:    DateRange{From: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+			want: []string{"2026-10-05 09:00-17:00", "2026-10-12 09:00-17:00"},
+		},
+		{
+			name:  "single event outside the range",
+			event: "DTSTART;TZID=Europe/Madrid:20260930T090000\r\nDTEND;TZID=Europe/Madrid:20260930T170000\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ics := icsFile("BEGIN:VEVENT\r\nUID:a\r\nSUMMARY:Work\r\n" + tt.event + "END:VEVENT\r\n")
+			r := tt.r
+			if r.From.IsZero() {
+				r = october
+			}
+			shifts, err := ReadICS(strings.NewReader(ics), ICSOptions{Location: madrid, Range: r})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, s := range shifts {
+				got = append(got, s.Date.Format("2006-01-02")+" "+s.ClockIn+"-"+s.ClockOut)
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("shifts = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadICSUnsupportedRule(t *testing.T) {
+	ics := icsFile("BEGIN:VEVENT\r\nUID:a\r\nSUMMARY:Work\r\nRRULE:FREQ=MONTHLY;BYMONTHDAY=1\r\n" +
+		"DTSTART:20261001T090000Z\r\nDTEND:20261001T170000Z\r\nEND:VEVENT\r\n")
+	_, err := ReadICS(strings.NewReader(ics), ICSOptions{Location: time.UTC})
+	if err == nil || !strings.Contains(err.Error(), `recurring event "Work"`) || !strings.Contains(err.Error(), "MONTHLY") {
+		t.Errorf("ReadICS = %v, want an error naming the event and its rule", err)
+	}
+}
@@ -0,0 +1,147 @@
+package factorial
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PlannedShift is a shift to record on a date, with its breaks
+type PlannedShift struct {
+	Date     time.Time
+	ClockIn  string
+	ClockOut string
+	Breaks   []Segment
+}
+
+// ImportOptions configures an import
+type ImportOptions struct {
+	DryRun bool
+	// Recovery applies when a shift with breaks fails half way, defaults to
+	// RecoveryRollback
+	Recovery Recovery
+	// Location is where the shifts are worked from, work_from_home if empty
+	Location string
+	// Overlap is what to do with shifts overlapping the ones already
+	// recorded, OverlapSkip or OverlapReplace. Defaults to OverlapSkip.
+	Overlap OverlapPolicy
+	// Backup is the file the shifts deleted by OverlapReplace are written to
+	// before deleting them, defaults to DefaultBackupPath
+	Backup string
+}
+
+// PlannedRange returns the days the shifts cover
+func PlannedRange(shifts []PlannedShift) DateRange {
+	var r DateRange
+	for _, s := range shifts {
+		if r.From.IsZero() || s.Date.Before(r.From) {
+			r.From = s.Date
+		}
+		if s.Date.After(r.To) {
+			r.To = s.Date
+		}
+	}
+	return r
+}
+
+// Import records planned shifts in date order, calling report with the
+// result of each one. months must cover the shifts, see LoadRange and
+// PlannedRange. Like ClockIn it skips leaves and non-laborable days, applies
+// the overlap policy to shifts overlapping the ones recorded, and returns the shifts that fail together in
+// a *RunError. Shifts replaced are written to opts.Backup before deleting
+// them.
+func (c *Client) Import(ctx context.Context, months []*Month, shifts []PlannedShift, opts ImportOptions, report func(DayResult)) error {
+	if opts.Recovery == "" {
+		opts.Recovery = RecoveryRollback
+	}
+	if opts.Overlap == "" {
+		opts.Overlap = OverlapSkip
+	}
+	if opts.Overlap == OverlapFillGaps {
+		return fmt.Errorf("%s only applies to clock in runs, imported shifts keep their times", OverlapFillGaps)
+	}
+	shifts = append([]PlannedShift(nil), shifts...)
+	sort.SliceStable(shifts, func(i, j int) bool {
+		if !shifts[i].Date.Equal(shifts[j].Date) {
+			return shifts[i].Date.Before(shifts[j].Date)
+		}
+		return shifts[i].ClockIn < shifts[j].ClockIn
+	})
+
+	backup := c.NewBackup()
+	var failed []*DayError
+	for _, p := range shifts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		m := findMonth(months, p.Date)
+		if m == nil {
+			return fmt.Errorf("%s is not in the months loaded", p.Date.Format("2006-01-02"))
+		}
+		result := DayResult{Date: m.Date(p.Date.Day()), DryRun: opts.DryRun}
+
+		day, ok := m.calendarDay(p.Date.Day())
+		if !ok {
+			result.Skipped, result.Reason = true, "Not in the calendar"
+			report(result)
+			continue
+		}
+		if skip, reason := m.shouldSkipDay(day, result.Date, ClockInOptions{}); skip {
+			result.Skipped, result.Reason = true, reason
+			report(result)
+			continue
+		}
+
+		shift := m.newShift(day, p.ClockIn, p.ClockOut)
+		if opts.Location != "" {
+			shift.LocationType = opts.Location
+		}
+		var replaced []Shift
+		var reason string
+		result.Shift, result.Breaks, replaced, result.Overlap, reason = m.applyOverlap(day.Day, shift, p.Breaks, opts.Overlap)
+		if reason != "" {
+			result.Skipped, result.Reason = true, reason
+			report(result)
+			continue
+		}
+		if !opts.DryRun && len(replaced) > 0 {
+			// The backup grows with each day replaced, and is written before
+			// deleting its shifts
+			backup.Add(m, replaced)
+			result.Backup, result.Err = saveReplaced(backup, opts.Backup)
+			if result.Err == nil {
+				opts.Backup = result.Backup
+			}
+		}
+		if !opts.DryRun && result.Err == nil {
+			result.Recovery, result.Err = c.replaceDay(ctx, m, result.Shift, result.Breaks, replaced, opts.Recovery)
+			if result.Err == nil {
+				m.dropShifts(replaced)
+			}
+		}
+		if result.Err != nil {
+			failed = append(failed, &DayError{Date: result.Date, Err: result.Err})
+		} else {
+			// Later shifts of the same day are checked against this one too
+			for _, s := range result.Segments() {
+				m.Shifts = append(m.Shifts, Shift{Day: day.Day, ClockIn: s.Start, ClockOut: s.End})
+			}
+		}
+		report(result)
+	}
+	if len(failed) > 0 {
+		return &RunError{Errors: failed}
+	}
+	return nil
+}
+
+// calendarDay returns a day of the month's calendar
+func (m *Month) calendarDay(day int) (CalendarDay, bool) {
+	for _, d := range m.Calendar {
+		if d.Day == day {
+			return d, true
+		}
+	}
+	return CalendarDay{}, false
+}
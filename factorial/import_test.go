@@ -0,0 +1,53 @@
+package factorial_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alejoar/factorialsucks/factorial"
+)
+
+func TestImportOverlap(t *testing.T) {
+	planned := []factorial.PlannedShift{{
+		Date:     time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC),
+		ClockIn:  "09:00",
+		ClockOut: "17:00",
+		Breaks:   []factorial.Segment{{Start: "13:00", End: "14:00"}},
+	}}
+	tests := []struct {
+		policy  factorial.OverlapPolicy
+		want    string
+		wantErr string
+	}{
+		{policy: factorial.OverlapSkip, want: "08:00-10:00"},
+		{policy: factorial.OverlapReplace, want: "09:00-13:00, 14:00-17:00"},
+		// Imported shifts keep their times, there's no expected time to fill
+		{policy: factorial.OverlapFillGaps, want: "08:00-10:00", wantErr: "fill-gaps only applies to clock in runs"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			s := newServer(t)
+			s.AddShift("2026-10-05", "08:00", "10:00")
+			c := login(t, s)
+			opts := factorial.ImportOptions{Overlap: tt.policy, Backup: filepath.Join(t.TempDir(), "replaced.json")}
+			err := c.Import(context.Background(), []*factorial.Month{loadMonth(t, c)}, planned, opts, func(factorial.DayResult) {})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Import = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			var got []string
+			for _, shift := range s.Shifts("2026-10-05") {
+				got = append(got, shift.ClockIn+"-"+shift.ClockOut)
+			}
+			if strings.Join(got, ", ") != tt.want {
+				t.Errorf("shifts = %q, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package factorial
+
+import (
+	"math/rand"
+	"time"
+)
+
+// jitterAttempts is how many random draws are tried before leaving a shift
+// as planned
+const jitterAttempts = 20
+
+// jitter moves the clock in and each break of a shift by a random number of
+// minutes up to max either way, and sets the clock out so that the worked
+// minutes are target, or the planned ones if target is 0. The draws depend
+// on the seed and the shift's date only, so a day always gets the same times
+// for a seed whatever the order days are processed in. The shift is returned
+// as planned if no draw keeps the times within the day and in order.
+func jitter(shift NewShift, breaks []Segment, target, max int, seed int64) (NewShift, []Segment) {
+	in, errIn := parseClock(shift.ClockIn)
+	out, errOut := parseClock(shift.ClockOut)
+	date, errDate := time.Parse("2006-01-02", shift.Date)
+	if max <= 0 || errIn != nil || errOut != nil || errDate != nil {
+		return shift, breaks
+	}
+	breakMinutes := 0
+	for _, b := range breaks {
+		start, errStart := parseClock(b.Start)
+		end, errEnd := parseClock(b.End)
+		if errStart != nil || errEnd != nil {
+			return shift, breaks
+		}
+		breakMinutes += end - start
+	}
+	if target <= 0 {
+		target = out - in - breakMinutes
+	}
+
+	r := rand.New(rand.NewSource(seed ^ date.Unix()))
+	offset := func() int { return r.Intn(2*max+1) - max }
+	for attempt := 0; attempt < jitterAttempts; attempt++ {
+		newIn := in + offset()
+		newOut := newIn + target + breakMinutes
+		if newIn < 0 || newOut >= 24*60 {
+			continue
+		}
+		moved := make([]Segment, 0, len(breaks))
+		valid := true
+		for _, b := range breaks {
+			start, _ := parseClock(b.Start)
+			end, _ := parseClock(b.End)
+			d := offset()
+			if start+d <= newIn || end+d >= newOut {
+				valid = false
+				break
+			}
+			moved = append(moved, Segment{Start: formatClock(start + d), End: formatClock(end + d)})
+		}
+		if !valid || checkTimes(formatClock(newIn), formatClock(newOut), moved) != nil {
+			continue
+		}
+		shift.ClockIn = formatClock(newIn)
+		shift.ClockOut = formatClock(newOut)
+		return shift, moved
+	}
+	return shift, breaks
+}
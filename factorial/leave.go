@@ -0,0 +1,75 @@
+package factorial
+
+import (
+	"fmt"
+	"math"
+)
+
+// fitLeaves moves the worked time of a shift out of the partial leaves of its
+// day. Work starts at the clock in, or once a leave covering it ends, and
+// stops for every leave and break until the shift's worked minutes are done,
+// so the time around a leave is recorded as separate segments. Breaks during
+// a leave are dropped.
+func fitLeaves(shift NewShift, breaks []Segment, day CalendarDay) (NewShift, []Segment, error) {
+	if !day.PartialLeave() {
+		return shift, breaks, nil
+	}
+	in, errIn := parseClock(shift.ClockIn)
+	out, errOut := parseClock(shift.ClockOut)
+	if errIn != nil || errOut != nil {
+		return shift, breaks, nil
+	}
+	var planned []span
+	worked := out - in
+	for _, b := range breaks {
+		start, errStart := parseClock(b.Start)
+		end, errEnd := parseClock(b.End)
+		if errStart != nil || errEnd != nil {
+			return shift, breaks, nil
+		}
+		planned = append(planned, span{start, end})
+		worked -= end - start
+	}
+	if worked <= 0 {
+		return shift, breaks, nil
+	}
+
+	var pauses []span
+	for _, l := range day.Leaves {
+		if w, ok := leaveWindow(l, in, worked); ok {
+			pauses = append(pauses, w)
+		}
+	}
+	segments := workAround(in, worked, withBreaks(pauses, planned))
+	if segments[len(segments)-1].end >= dayMinutes {
+		return shift, breaks, fmt.Errorf("%s: the time left doesn't fit in the day", day.Leaves[0].Name)
+	}
+	shift, gaps := fromSpans(shift, segments)
+	return shift, gaps, nil
+}
+
+// leaveWindow returns the time a partial leave takes. A morning off without
+// times lasts its minutes from the clock in, or as long as the time left to
+// work if they're unknown. Afternoons off without times come after the work
+// and don't need a window.
+func leaveWindow(l CalendarLeave, in, worked int) (span, bool) {
+	minutes := int(math.Round(l.Minutes))
+	switch {
+	case l.StartTime != "":
+		start, err := parseClock(l.StartTime)
+		if err != nil {
+			return span{}, false
+		}
+		end, err := parseClock(l.EndTime)
+		if err != nil {
+			end = start + minutes
+		}
+		return span{start, end}, end > start
+	case l.HalfDay == HalfDayMorning:
+		if minutes <= 0 {
+			minutes = worked
+		}
+		return span{0, in + minutes}, true
+	}
+	return span{}, false
+}
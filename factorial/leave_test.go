@@ -0,0 +1,122 @@
+package factorial
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLeaveWindow(t *testing.T) {
+	in := 8*60 + 45
+	tests := []struct {
+		name  string
+		leave CalendarLeave
+		want  string
+	}{
+		{"timed", CalendarLeave{StartTime: "10:00", EndTime: "12:00", Minutes: 120}, "10:00-12:00"},
+		{"timed without an end", CalendarLeave{StartTime: "10:00", Minutes: 90}, "10:00-11:30"},
+		{"timed ending before it starts", CalendarLeave{StartTime: "12:00", EndTime: "10:00"}, "none"},
+		{"invalid start", CalendarLeave{StartTime: "noon", EndTime: "13:00"}, "none"},
+		{"morning with minutes", CalendarLeave{HalfDay: HalfDayMorning, Minutes: 180}, "00:00-11:45"},
+		{"morning without minutes", CalendarLeave{HalfDay: HalfDayMorning}, "00:00-12:53"},
+		{"afternoon", CalendarLeave{HalfDay: HalfDayAfternoon, Minutes: 240}, "none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := "none"
+			if w, ok := leaveWindow(tt.leave, in, 248); ok {
+				got = formatClock(w.start) + "-" + formatClock(w.end)
+			}
+			if got != tt.want {
+				t.Errorf("leaveWindow = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFitLeaves(t *testing.T) {
+	lunch := []Segment{{Start: "14:30", End: "15:00"}}
+	tests := []struct {
+		name    string
+		in, out string
+		breaks  []Segment
+		leaves  []CalendarLeave
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "no partial leave",
+			in:     "08:45",
+			out:    "17:30",
+			breaks: lunch,
+			leaves: []CalendarLeave{{Name: "Vacation"}},
+			want:   "08:45-17:30 break 14:30-15:00",
+		},
+		{
+			name:   "afternoon off",
+			in:     "08:45",
+			out:    "12:53",
+			leaves: []CalendarLeave{{HalfDay: HalfDayAfternoon}},
+			want:   "08:45-12:53",
+		},
+		{
+			name:   "morning off",
+			in:     "08:45",
+			out:    "12:45",
+			leaves: []CalendarLeave{{HalfDay: HalfDayMorning, Minutes: 240}},
+			want:   "12:45-16:45",
+		},
+		{
+			name:   "leave before the lunch break",
+			in:     "08:45",
+			out:    "16:30",
+			breaks: lunch,
+			leaves: []CalendarLeave{{StartTime: "10:00", EndTime: "11:00"}},
+			want:   "08:45-17:30 break 10:00-11:00 break 14:30-15:00",
+		},
+		{
+			name:   "leave covering the lunch break",
+			in:     "08:45",
+			out:    "17:30",
+			breaks: lunch,
+			leaves: []CalendarLeave{{StartTime: "14:00", EndTime: "15:30"}},
+			want:   "08:45-18:30 break 14:00-15:30",
+		},
+		{
+			name:   "two leaves",
+			in:     "08:45",
+			out:    "14:45",
+			leaves: []CalendarLeave{{StartTime: "09:00", EndTime: "10:00"}, {StartTime: "12:00", EndTime: "13:00"}},
+			want:   "08:45-16:45 break 09:00-10:00 break 12:00-13:00",
+		},
+		{
+			name:    "time left past midnight",
+			in:      "08:45",
+			out:     "17:15",
+			breaks:  lunch,
+			leaves:  []CalendarLeave{{Name: "Training", StartTime: "09:00", EndTime: "21:00"}},
+			wantErr: "Training: the time left doesn't fit in the day",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day := CalendarDay{Date: "2026-10-06", Day: 6, IsLeave: true, MinutesLeft: 240, Leaves: tt.leaves}
+			shift, breaks, err := fitLeaves(NewShift{ClockIn: tt.in, ClockOut: tt.out}, tt.breaks, day)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("fitLeaves = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fitLeaves: %v", err)
+			}
+			got := shift.ClockIn + "-" + shift.ClockOut
+			for _, b := range breaks {
+				got += " break " + b.Start + "-" + b.End
+			}
+			if got != tt.want {
+				t.Errorf("fitLeaves = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
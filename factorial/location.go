@@ -0,0 +1,128 @@
+package factorial
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Location types
+const (
+	LocationOffice       = "office"
+	LocationRemote       = "work_from_home"
+	LocationBusinessTrip = "business_trip"
+)
+
+// ParseLocation validates a location type, remote and home are accepted for
+// work_from_home
+func ParseLocation(s string) (string, error) {
+	switch l := strings.ToLower(s); l {
+	case LocationOffice, LocationRemote, LocationBusinessTrip:
+		return l, nil
+	case "remote", "home":
+		return LocationRemote, nil
+	}
+	return "", fmt.Errorf("invalid location %q, expected %s, %s or %s", s, LocationOffice, LocationRemote, LocationBusinessTrip)
+}
+
+// LocationRule sets where the days it matches are worked from. Days match
+// on Weekdays and Dates (YYYY-MM-DD or MM-DD), unset matchers match every
+// day. With After, only the segments starting at or after that time match.
+type LocationRule struct {
+	Weekdays []string `yaml:"weekdays,omitempty" json:"weekdays,omitempty"`
+	Dates    []string `yaml:"dates,omitempty" json:"dates,omitempty"`
+	After    string   `yaml:"after,omitempty" json:"after,omitempty"`
+	Location string   `yaml:"location" json:"location"`
+}
+
+func (r LocationRule) validate() error {
+	for _, w := range r.Weekdays {
+		if _, err := parseWeekday(w); err != nil {
+			return err
+		}
+	}
+	for _, d := range r.Dates {
+		if _, _, err := parseDate(d); err != nil {
+			return err
+		}
+	}
+	if r.After != "" {
+		if _, err := parseClock(r.After); err != nil {
+			return err
+		}
+	}
+	_, err := ParseLocation(r.Location)
+	return err
+}
+
+func (r LocationRule) matches(date time.Time, start int) bool {
+	if len(r.Weekdays) > 0 {
+		found := false
+		for _, w := range r.Weekdays {
+			if wd, _ := parseWeekday(w); wd == date.Weekday() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(r.Dates) > 0 {
+		found := false
+		for _, d := range r.Dates {
+			if (DateSpan{From: d, To: d}).contains(date) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.After != "" {
+		if after, _ := parseClock(r.After); start < after {
+			return false
+		}
+	}
+	return true
+}
+
+// location returns where a segment starting at start (minutes since
+// midnight) on date is worked from: the location of the first rule matching
+// it, or work_from_home
+func (s *Schedule) location(date time.Time, start int) string {
+	for _, r := range s.Locations {
+		if r.matches(date, start) {
+			l, _ := ParseLocation(r.Location)
+			return l
+		}
+	}
+	return LocationRemote
+}
+
+// setLocations sets where each worked segment of a shift is worked from,
+// override for all of them if set, or as the schedule's location rules say.
+// The shift's location is the first segment's, and Locations lists them all
+// when they differ.
+func setLocations(shift *NewShift, breaks []Segment, date time.Time, s *Schedule, override string) {
+	starts := []string{shift.ClockIn}
+	for _, b := range breaks {
+		starts = append(starts, b.End)
+	}
+	locations := make([]string, len(starts))
+	differ := false
+	for i, start := range starts {
+		locations[i] = override
+		if override == "" {
+			minutes, _ := parseClock(start)
+			locations[i] = s.location(date, minutes)
+		}
+		differ = differ || locations[i] != locations[0]
+	}
+	shift.LocationType = locations[0]
+	shift.Locations = nil
+	if differ {
+		shift.Locations = locations
+	}
+}
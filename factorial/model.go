@@ -1,27 +1,6 @@
 package factorial
 
-import "net/http"
-
-type factorialClient struct {
-	http.Client
-	employeeId int
-	periodId   int
-	calendar   []calendarDay
-	shifts     []shift
-	year       int
-	month      int
-	clockIn    string
-	clockOut   string
-	todayOnly  bool
-	untilToday bool
-}
-
-type period struct {
-	Id         int
-	EmployeeId int `json:"employee_id"`
-	Year       int
-	Month      int
-}
+// Period is an attendance period as returned by /attendance/periods
 type Period struct {
 	Id                                          int       `json:"id"`
 	EmployeeId                                  int       `json:"employee_id"`
@@ -56,17 +35,71 @@ type Period struct {
 	} `json:"permissions"`
 	Reviews []interface{} `json:"reviews"`
 }
-type calendarDay struct {
-	Id               string
-	Day              int
-	DayBeforeHoliday bool `json:"day_before_holiday"`
-	Date             string
+
+// PeriodsQuery filters the periods returned by Client.Periods, zero values
+// are left out of the request
+type PeriodsQuery struct {
+	Year       int
+	Month      int
+	EmployeeId int
+	StartOn    string
+	EndOn      string
+}
+
+// CalendarDay is a day of an employee's attendance calendar
+type CalendarDay struct {
+	Id               string  `json:"id"`
+	Day              int     `json:"day"`
+	DayBeforeHoliday bool    `json:"day_before_holiday"`
+	Date             string  `json:"date"`
 	IsLaborable      bool    `json:"is_laborable"`
 	IsLeave          bool    `json:"is_leave"`
 	LeaveName        string  `json:"leave_name"`
 	MinutesLeft      float64 `json:"minutes_left"`
+	// Leaves details the leaves of the day, partial ones only take part of it
+	Leaves []CalendarLeave `json:"leaves"`
 }
-type newShift struct {
+
+// Half day leaves
+const (
+	HalfDayMorning   = "beginning_of_day"
+	HalfDayAfternoon = "end_of_day"
+)
+
+// CalendarLeave is a leave on a calendar day. Leaves with neither HalfDay nor
+// StartTime take the whole day.
+type CalendarLeave struct {
+	Name string `json:"name"`
+	// HalfDay is HalfDayMorning or HalfDayAfternoon for half day leaves
+	HalfDay string `json:"half_day"`
+	// StartTime and EndTime bound a leave of some hours, in HH:MM
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	// Minutes is how long a partial leave lasts, if known
+	Minutes float64 `json:"minutes"`
+}
+
+// Partial reports whether the leave takes only part of the day
+func (l CalendarLeave) Partial() bool {
+	return l.HalfDay != "" || l.StartTime != ""
+}
+
+// PartialLeave reports whether the day has leaves that all take only part of
+// it, and minutes left to work
+func (d CalendarDay) PartialLeave() bool {
+	if len(d.Leaves) == 0 || d.MinutesLeft <= 0 {
+		return false
+	}
+	for _, l := range d.Leaves {
+		if !l.Partial() {
+			return false
+		}
+	}
+	return true
+}
+
+// NewShift is the payload used to create a shift
+type NewShift struct {
 	ClockIn                          string      `json:"clock_in"`
 	ClockOut                         string      `json:"clock_out"`
 	Day                              int         `json:"day"`
@@ -78,26 +111,28 @@ type newShift struct {
 	Date                             string      `json:"date"`
 	Source                           string      `json:"source"`
 	ReferenceDate                    string      `json:"reference_date"`
+	// Locations lists where each worked segment is worked from when they
+	// differ, they're recorded through the break endpoints
+	Locations []string `json:"-"`
 }
 
-type shift struct {
+// Shift is a shift already recorded in Factorial
+type Shift struct {
 	Id           int64  `json:"id"`
 	PeriodId     int64  `json:"period_id"`
 	Day          int    `json:"day"`
 	ClockIn      string `json:"clock_in"`
 	LocationType string `json:"location_type"`
-	Source       string `json:"desktop"`
+	Source       string `json:"source"`
 	ClockOut     string `json:"clock_out"`
 	Minutes      int64  `json:"minutes"`
 }
 
-type breakShift struct {
+// BreakShift is the payload of the clock in, break and clock out endpoints.
+// Now is an RFC 3339 timestamp, the location is only sent when clocking in
+// or, if it changes, ending a break.
+type BreakShift struct {
 	EmployeeId   int    `json:"employee_id"`
 	Now          string `json:"now"`
-	LocationType string `json:"location_type"`
-}
-
-type breakShiftOut struct {
-	EmployeeId int    `json:"employee_id"`
-	Now        string `json:"now"`
+	LocationType string `json:"location_type,omitempty"`
 }
@@ -0,0 +1,154 @@
+package factorial
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Month is the attendance data of the logged in employee for a month
+type Month struct {
+	Year       int
+	Month      int
+	EmployeeId int
+	PeriodId   int
+	Period     Period
+	Calendar   []CalendarDay
+	Shifts     []Shift
+	// Range limits the days clocking in, resets and the status work on, the
+	// whole month when it's zero
+	Range DateRange
+}
+
+// DateRange is an inclusive range of days
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// ParseDateRange parses a range of YYYY-MM-DD dates
+func ParseDateRange(from, to string) (DateRange, error) {
+	var r DateRange
+	var err error
+	if r.From, err = time.Parse("2006-01-02", from); err != nil {
+		return r, fmt.Errorf("invalid date %q, use YYYY-MM-DD", from)
+	}
+	if r.To, err = time.Parse("2006-01-02", to); err != nil {
+		return r, fmt.Errorf("invalid date %q, use YYYY-MM-DD", to)
+	}
+	if r.To.Before(r.From) {
+		return r, fmt.Errorf("the range ends on %s, before it starts on %s", to, from)
+	}
+	return r, nil
+}
+
+// MonthRange returns the range covering a whole month
+func MonthRange(year, month int) DateRange {
+	from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	return DateRange{From: from, To: from.AddDate(0, 1, -1)}
+}
+
+// Contains reports whether date falls on one of the days of the range
+func (r DateRange) Contains(date time.Time) bool {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return !day.Before(r.From) && !day.After(r.To)
+}
+
+// LoadRange loads every month the range covers, limiting each one to the days
+// of the range
+func (c *Client) LoadRange(ctx context.Context, r DateRange) ([]*Month, error) {
+	var months []*Month
+	first := time.Date(r.From.Year(), r.From.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for date := first; !date.After(r.To); date = date.AddDate(0, 1, 0) {
+		m, err := c.LoadMonth(ctx, date.Year(), int(date.Month()))
+		if err != nil {
+			return nil, err
+		}
+		if r.From.After(m.Range.From) {
+			m.Range.From = r.From
+		}
+		if r.To.Before(m.Range.To) {
+			m.Range.To = r.To
+		}
+		months = append(months, m)
+	}
+	return months, nil
+}
+
+// LoadMonth fetches the period, calendar and shifts for the given month
+func (c *Client) LoadMonth(ctx context.Context, year, month int) (*Month, error) {
+	m := &Month{Year: year, Month: month, Range: MonthRange(year, month)}
+
+	periods, err := c.Periods(ctx, PeriodsQuery{Year: year, Month: month})
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, p := range periods {
+		if p.Year == year && p.Month == month {
+			m.EmployeeId = p.EmployeeId
+			m.PeriodId = p.Id
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, &PeriodNotFoundError{Year: year, Month: month}
+	}
+
+	m.Calendar, err = c.Calendar(ctx, m.EmployeeId, year, month)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.setMinutesLeft(ctx, m); err != nil {
+		return nil, err
+	}
+
+	m.Shifts, err = c.Shifts(ctx, m.EmployeeId, year, month)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// setMinutesLeft fills in the expected minutes for each day of the calendar
+func (c *Client) setMinutesLeft(ctx context.Context, m *Month) error {
+	if len(m.Calendar) == 0 {
+		return nil
+	}
+	periods, err := c.Periods(ctx, PeriodsQuery{
+		Year:       m.Year,
+		Month:      m.Month,
+		EmployeeId: m.EmployeeId,
+		StartOn:    m.Calendar[0].Date,
+		EndOn:      m.Calendar[len(m.Calendar)-1].Date,
+	})
+	if err != nil {
+		return err
+	}
+	if len(periods) == 0 {
+		return &PeriodNotFoundError{Year: m.Year, Month: m.Month}
+	}
+	m.Period = periods[0]
+	for i := range m.Calendar {
+		if i < len(m.Period.EstimatedRegularMinutesDistribution) {
+			m.Calendar[i].MinutesLeft = m.Period.EstimatedRegularMinutesDistribution[i]
+		}
+	}
+	return nil
+}
+
+// Date returns the date of a day of the month. Dates are kept at midnight
+// UTC, where adding days is never off by a DST change; the client's time
+// zone only applies to the times within a day.
+func (m *Month) Date(day int) time.Time {
+	return time.Date(m.Year, time.Month(m.Month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// InRange reports whether a day of the month is within its range
+func (m *Month) InRange(day int) bool {
+	if m.Range.From.IsZero() && m.Range.To.IsZero() {
+		return true
+	}
+	return m.Range.Contains(m.Date(day))
+}
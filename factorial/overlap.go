@@ -0,0 +1,352 @@
+package factorial
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// OverlapPolicy decides what happens to a day whose planned segments overlap
+// shifts already recorded
+type OverlapPolicy string
+
+const (
+	// OverlapSkip leaves the day as it is
+	OverlapSkip OverlapPolicy = "skip"
+	// OverlapFillGaps records the day's expected minutes less the ones
+	// already tracked, around them, see fillGaps. Only clock in runs take it,
+	// imported shifts keep their times.
+	OverlapFillGaps OverlapPolicy = "fill-gaps"
+	// OverlapReplace deletes the overlapping shifts of the day and records the
+	// planned ones
+	OverlapReplace OverlapPolicy = "replace"
+)
+
+// ParseOverlapPolicy validates an overlap policy name
+func ParseOverlapPolicy(s string) (OverlapPolicy, error) {
+	switch p := OverlapPolicy(s); p {
+	case OverlapSkip, OverlapFillGaps, OverlapReplace:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid overlap policy %q, expected %s, %s or %s", s, OverlapSkip, OverlapFillGaps, OverlapReplace)
+}
+
+// dayMinutes is the end of a day in minutes since midnight
+const dayMinutes = 24 * 60
+
+// span is a time window in minutes since midnight, its end excluded
+type span struct {
+	start, end int
+}
+
+func (s span) overlaps(o span) bool {
+	return s.start < o.end && o.start < s.end
+}
+
+// recorded is a shift recorded in Factorial and the part of a day it takes
+type recorded struct {
+	shift Shift
+	span  span
+	// carried is set for the part of an overnight shift after midnight,
+	// which belongs to the day before
+	carried bool
+}
+
+// shiftSpan returns the part of its day a recorded shift takes. Open shifts
+// last until midnight, as do overnight shifts, whose clock out is before
+// their clock in.
+func shiftSpan(s Shift) (span, bool) {
+	in, err := parseClock(s.ClockIn)
+	if err != nil {
+		return span{}, false
+	}
+	if s.ClockOut == "" {
+		return span{in, dayMinutes}, true
+	}
+	out, err := parseClock(s.ClockOut)
+	if err != nil {
+		return span{}, false
+	}
+	if out <= in {
+		out = dayMinutes
+	}
+	return span{in, out}, true
+}
+
+// recordedSpans returns the shifts taking part of a day of the month,
+// including the end of overnight shifts of the day before
+func (m *Month) recordedSpans(day int) []recorded {
+	var spans []recorded
+	for _, s := range m.Shifts {
+		switch s.Day {
+		case day:
+			if w, ok := shiftSpan(s); ok {
+				spans = append(spans, recorded{shift: s, span: w})
+			}
+		case day - 1:
+			in, errIn := parseClock(s.ClockIn)
+			out, errOut := parseClock(s.ClockOut)
+			if errIn == nil && errOut == nil && out < in && out > 0 {
+				spans = append(spans, recorded{shift: s, span: span{0, out}, carried: true})
+			}
+		}
+	}
+	return spans
+}
+
+// overlapping returns the shifts of a day overlapping any of the windows, in
+// the order they start
+func (m *Month) overlapping(day int, windows []span) []recorded {
+	var found []recorded
+	for _, r := range m.recordedSpans(day) {
+		for _, w := range windows {
+			if r.span.overlaps(w) {
+				found = append(found, r)
+				break
+			}
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].span.start < found[j].span.start
+	})
+	return found
+}
+
+// describeOverlap lists overlapping shifts as HH:MM - HH:MM, open shifts
+// ending in "open"
+func describeOverlap(shifts []recorded) string {
+	var times []string
+	for _, r := range shifts {
+		out := r.shift.ClockOut
+		if out == "" {
+			out = "open"
+		}
+		times = append(times, r.shift.ClockIn+" - "+out)
+	}
+	return strings.Join(times, ", ")
+}
+
+// plannedSpans returns the worked windows of a shift and its breaks
+func plannedSpans(shift NewShift, breaks []Segment) []span {
+	var spans []span
+	for _, s := range (DayResult{Shift: shift, Breaks: breaks}).Segments() {
+		start, errStart := parseClock(s.Start)
+		end, errEnd := parseClock(s.End)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+		if end <= start {
+			end = dayMinutes
+		}
+		spans = append(spans, span{start, end})
+	}
+	return spans
+}
+
+// withBreaks adds the breaks not overlapping any of the pauses to them
+func withBreaks(pauses, breaks []span) []span {
+	fixed := len(pauses)
+	for _, b := range breaks {
+		overlaps := false
+		for _, p := range pauses[:fixed] {
+			if b.overlaps(p) {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			pauses = append(pauses, b)
+		}
+	}
+	return pauses
+}
+
+// workAround returns the windows worked from start for the given minutes,
+// stopping for every pause. Work starting during a pause waits for its end.
+func workAround(start, worked int, pauses []span) []span {
+	pauses = append([]span(nil), pauses...)
+	sort.Slice(pauses, func(i, j int) bool {
+		return pauses[i].start < pauses[j].start
+	})
+
+	var segments []span
+	cursor, remaining := start, worked
+	for _, p := range pauses {
+		if remaining == 0 {
+			break
+		}
+		if p.end <= cursor {
+			continue
+		}
+		if p.start > cursor {
+			work := p.start - cursor
+			if work > remaining {
+				work = remaining
+			}
+			segments = append(segments, span{cursor, cursor + work})
+			remaining -= work
+		}
+		cursor = p.end
+	}
+	if remaining > 0 {
+		segments = append(segments, span{cursor, cursor + remaining})
+	}
+	return segments
+}
+
+// fromSpans returns a shift recording the windows, in order, with the gaps
+// between them as breaks. The segments are worked from the shift's location,
+// see setLocations.
+func fromSpans(shift NewShift, windows []span) (NewShift, []Segment) {
+	shift.Locations = nil
+	shift.ClockIn = formatClock(windows[0].start)
+	shift.ClockOut = formatClock(windows[len(windows)-1].end)
+	var breaks []Segment
+	for i := 1; i < len(windows); i++ {
+		breaks = append(breaks, Segment{Start: formatClock(windows[i-1].end), End: formatClock(windows[i].start)})
+	}
+	return shift, breaks
+}
+
+// applyOverlap checks the planned shift of a day against the shifts already
+// recorded and applies OverlapSkip or OverlapReplace, OverlapFillGaps goes
+// through fillGaps instead. It returns the shift to record, or a skip reason,
+// and the shifts to delete first with OverlapReplace. The note says what was
+// done about the overlap.
+func (m *Month) applyOverlap(day int, shift NewShift, breaks []Segment, policy OverlapPolicy) (NewShift, []Segment, []Shift, string, string) {
+	found := m.overlapping(day, plannedSpans(shift, breaks))
+	if len(found) == 0 {
+		return shift, breaks, nil, "", ""
+	}
+	overlap := describeOverlap(found)
+
+	if policy == OverlapReplace {
+		var replaced []Shift
+		for _, r := range found {
+			switch {
+			case r.carried:
+				return shift, breaks, nil, "", fmt.Sprintf("Period overlap with the day before: %s", describeOverlap([]recorded{r}))
+			case r.shift.Id == 0:
+				// Recorded earlier in the same run
+				return shift, breaks, nil, "", fmt.Sprintf("Period overlap: %s", describeOverlap([]recorded{r}))
+			}
+			replaced = append(replaced, r.shift)
+		}
+		return shift, breaks, replaced, fmt.Sprintf("replaced %s", overlap), ""
+	}
+	return shift, breaks, nil, "", fmt.Sprintf("Period overlap: %s", overlap)
+}
+
+// fillGaps plans the time of a day still to work: its expected minutes, or
+// the shift's if unknown, less the minutes of the shifts already recorded.
+// The work starts at the shift's clock in and stops for the recorded shifts
+// and the planned breaks, so it never overlaps them. It returns the shift to
+// record, or a skip reason, and a note on the shifts worked around.
+func (m *Month) fillGaps(day CalendarDay, shift NewShift, breaks []Segment) (NewShift, []Segment, string, string) {
+	found := m.recordedSpans(day.Day)
+	if len(found) == 0 {
+		return shift, breaks, "", ""
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].span.start < found[j].span.start
+	})
+	existing := describeOverlap(found)
+
+	planned := plannedSpans(shift, breaks)
+	target := int(math.Round(day.MinutesLeft))
+	if target <= 0 {
+		for _, s := range planned {
+			target += s.end - s.start
+		}
+	}
+	var pauses []span
+	for _, r := range found {
+		pauses = append(pauses, r.span)
+		// Open shifts and the end of the day before don't count yet
+		if !r.carried && r.shift.ClockOut != "" {
+			target -= r.span.end - r.span.start
+		}
+	}
+	if target <= 0 || len(planned) == 0 {
+		return shift, breaks, "", fmt.Sprintf("Already recorded: %s", existing)
+	}
+
+	var breakSpans []span
+	for i := 1; i < len(planned); i++ {
+		breakSpans = append(breakSpans, span{planned[i-1].end, planned[i].start})
+	}
+	segments := workAround(planned[0].start, target, withBreaks(pauses, breakSpans))
+	if segments[len(segments)-1].end >= dayMinutes {
+		return shift, breaks, "", fmt.Sprintf("The time left doesn't fit in the day around %s", existing)
+	}
+	shift, breaks = fromSpans(shift, segments)
+	return shift, breaks, fmt.Sprintf("filled around %s", existing), ""
+}
+
+// saveReplaced writes the backup of the shifts about to be replaced to path,
+// a new file in the config directory if empty. It returns the path written.
+func saveReplaced(b *Backup, path string) (string, error) {
+	if path == "" {
+		var err error
+		if path, err = DefaultBackupPath("replace", b.SavedAt); err != nil {
+			return "", fmt.Errorf("could not write the backup: %w", err)
+		}
+	}
+	if err := b.Save(path); err != nil {
+		return "", fmt.Errorf("could not write the backup: %w", err)
+	}
+	return path, nil
+}
+
+// replaceDay deletes the shifts a day replaces, which must be in a backup
+// already, and records its shift. When that fails the deleted shifts are
+// created again. The returned note says how a failure was dealt with.
+func (c *Client) replaceDay(ctx context.Context, m *Month, shift NewShift, breaks []Segment, replaced []Shift, recovery Recovery) (string, error) {
+	var note string
+	deleted, err := c.replaceShifts(ctx, replaced)
+	if err == nil {
+		if note, err = c.addShift(ctx, m, shift, breaks, recovery); err == nil {
+			return note, nil
+		}
+	}
+	if len(deleted) == 0 {
+		return note, err
+	}
+
+	restored := "the replaced shifts were created again"
+	for i, s := range deleted {
+		if restoreErr := c.CreateShift(ctx, m.recreatedShift(s.Day, s)); restoreErr != nil {
+			restored = fmt.Sprintf("%d replaced shift(s) couldn't be created again, they're in the backup: %v", len(deleted)-i, restoreErr)
+			break
+		}
+	}
+	if note != "" {
+		restored = note + ", " + restored
+	}
+	return restored, err
+}
+
+// replaceShifts deletes the shifts a planned day replaces, returning the ones
+// deleted
+func (c *Client) replaceShifts(ctx context.Context, shifts []Shift) ([]Shift, error) {
+	for i, s := range shifts {
+		if err := c.DeleteShift(ctx, s.Id); err != nil {
+			return shifts[:i], fmt.Errorf("replacing %s - %s: %w", s.ClockIn, s.ClockOut, err)
+		}
+	}
+	return shifts, nil
+}
+
+// dropShifts removes shifts deleted from the month
+func (m *Month) dropShifts(shifts []Shift) {
+	for _, s := range shifts {
+		for i, existing := range m.Shifts {
+			if existing.Id == s.Id {
+				m.Shifts = append(m.Shifts[:i], m.Shifts[i+1:]...)
+				break
+			}
+		}
+	}
+}
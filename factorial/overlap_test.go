@@ -0,0 +1,194 @@
+package factorial
+
+import (
+	"reflect"
+	"testing"
+)
+
+// plannedShift and plannedBreaks are the built-in schedule of a regular day,
+// 495 minutes with a lunch break
+var (
+	plannedShift  = NewShift{ClockIn: "08:45", ClockOut: "17:30", Day: 6, LocationType: LocationRemote}
+	plannedBreaks = []Segment{{Start: "14:30", End: "15:00"}}
+)
+
+// segmentTimes lists the worked windows of a shift as HH:MM - HH:MM
+func segmentTimes(shift NewShift, breaks []Segment) []string {
+	var times []string
+	for _, s := range (DayResult{Shift: shift, Breaks: breaks}).Segments() {
+		times = append(times, s.Start+" - "+s.End)
+	}
+	return times
+}
+
+func TestShiftSpan(t *testing.T) {
+	tests := []struct {
+		name              string
+		clockIn, clockOut string
+		want              span
+		ok                bool
+	}{
+		{"closed", "09:00", "13:00", span{540, 780}, true},
+		{"open", "09:00", "", span{540, dayMinutes}, true},
+		{"overnight", "22:00", "06:00", span{1320, dayMinutes}, true},
+		{"ending at midnight", "22:00", "00:00", span{1320, dayMinutes}, true},
+		{"invalid", "9am", "13:00", span{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := shiftSpan(Shift{ClockIn: tt.clockIn, ClockOut: tt.clockOut})
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("shiftSpan(%s - %s) = %v, %v, want %v, %v", tt.clockIn, tt.clockOut, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRecordedSpans(t *testing.T) {
+	m := &Month{Shifts: []Shift{
+		{Id: 1, Day: 5, ClockIn: "09:00", ClockOut: "17:00"},
+		{Id: 2, Day: 5, ClockIn: "22:00", ClockOut: "06:00"},
+		{Id: 3, Day: 5, ClockIn: "20:00", ClockOut: "00:00"},
+		{Id: 4, Day: 5, ClockIn: "23:00"},
+		{Id: 5, Day: 6, ClockIn: "09:00", ClockOut: "13:00"},
+		{Id: 6, Day: 7, ClockIn: "01:00", ClockOut: "02:00"},
+	}}
+
+	var got []recorded
+	for _, r := range m.recordedSpans(6) {
+		got = append(got, recorded{shift: Shift{Id: r.shift.Id}, span: r.span, carried: r.carried})
+	}
+	// Only the shift of the day before ending after midnight carries over
+	want := []recorded{
+		{shift: Shift{Id: 2}, span: span{0, 360}, carried: true},
+		{shift: Shift{Id: 5}, span: span{540, 780}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("recordedSpans(6) = %+v, want %+v", got, want)
+	}
+}
+
+func TestApplyOverlap(t *testing.T) {
+	tests := []struct {
+		name     string
+		shifts   []Shift
+		policy   OverlapPolicy
+		segments []string
+		replaced []int64
+		note     string
+		reason   string
+	}{
+		{
+			name:     "no overlap",
+			shifts:   []Shift{{Id: 1, Day: 6, ClockIn: "18:00", ClockOut: "19:00"}},
+			policy:   OverlapSkip,
+			segments: []string{"08:45 - 14:30", "15:00 - 17:30"},
+		},
+		{
+			name:   "skip",
+			shifts: []Shift{{Id: 1, Day: 6, ClockIn: "09:00", ClockOut: "13:00"}},
+			policy: OverlapSkip,
+			reason: "Period overlap: 09:00 - 13:00",
+		},
+		{
+			name: "replace",
+			shifts: []Shift{
+				{Id: 1, Day: 6, ClockIn: "16:00", ClockOut: "17:00"},
+				{Id: 2, Day: 6, ClockIn: "09:00", ClockOut: "13:00"},
+			},
+			policy:   OverlapReplace,
+			segments: []string{"08:45 - 14:30", "15:00 - 17:30"},
+			replaced: []int64{2, 1},
+			note:     "replaced 09:00 - 13:00, 16:00 - 17:00",
+		},
+		{
+			name:   "replace a shift of the day before",
+			shifts: []Shift{{Id: 1, Day: 5, ClockIn: "22:00", ClockOut: "10:00"}},
+			policy: OverlapReplace,
+			reason: "Period overlap with the day before: 22:00 - 10:00",
+		},
+		{
+			name:   "replace a shift of the same run",
+			shifts: []Shift{{Day: 6, ClockIn: "09:00", ClockOut: "13:00"}},
+			policy: OverlapReplace,
+			reason: "Period overlap: 09:00 - 13:00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Month{Shifts: tt.shifts}
+			shift, breaks, replaced, note, reason := m.applyOverlap(6, plannedShift, plannedBreaks, tt.policy)
+			if reason != tt.reason || note != tt.note {
+				t.Errorf("note, reason = %q, %q, want %q, %q", note, reason, tt.note, tt.reason)
+			}
+			if reason == "" && !reflect.DeepEqual(segmentTimes(shift, breaks), tt.segments) {
+				t.Errorf("segments = %q, want %q", segmentTimes(shift, breaks), tt.segments)
+			}
+			var ids []int64
+			for _, s := range replaced {
+				ids = append(ids, s.Id)
+			}
+			if !reflect.DeepEqual(ids, tt.replaced) {
+				t.Errorf("replaced = %v, want %v", ids, tt.replaced)
+			}
+		})
+	}
+}
+
+func TestFillGaps(t *testing.T) {
+	tests := []struct {
+		name        string
+		shifts      []Shift
+		minutesLeft float64
+		segments    []string
+		note        string
+		reason      string
+	}{
+		{
+			name:        "nothing recorded",
+			minutesLeft: 495,
+			segments:    []string{"08:45 - 14:30", "15:00 - 17:30"},
+		},
+		{
+			name:        "morning recorded by hand",
+			shifts:      []Shift{{Id: 1, Day: 6, ClockIn: "09:00", ClockOut: "13:00"}},
+			minutesLeft: 495,
+			segments:    []string{"08:45 - 09:00", "13:00 - 14:30", "15:00 - 17:30"},
+			note:        "filled around 09:00 - 13:00",
+		},
+		{
+			name:     "expected minutes unknown",
+			shifts:   []Shift{{Id: 1, Day: 6, ClockIn: "09:00", ClockOut: "13:00"}},
+			segments: []string{"08:45 - 09:00", "13:00 - 14:30", "15:00 - 17:30"},
+			note:     "filled around 09:00 - 13:00",
+		},
+		{
+			name: "day complete",
+			shifts: []Shift{
+				{Id: 1, Day: 6, ClockIn: "07:00", ClockOut: "12:00"},
+				{Id: 2, Day: 6, ClockIn: "18:00", ClockOut: "21:15"},
+			},
+			minutesLeft: 495,
+			reason:      "Already recorded: 07:00 - 12:00, 18:00 - 21:15",
+		},
+		{
+			name:        "not fitting before midnight",
+			shifts:      []Shift{{Id: 1, Day: 6, ClockIn: "20:00", ClockOut: "23:00"}},
+			minutesLeft: 900,
+			reason:      "The time left doesn't fit in the day around 20:00 - 23:00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Month{Shifts: tt.shifts}
+			day := CalendarDay{Day: 6, Date: "2026-10-06", IsLaborable: true, MinutesLeft: tt.minutesLeft}
+			shift, breaks, note, reason := m.fillGaps(day, plannedShift, plannedBreaks)
+			if reason != tt.reason || note != tt.note {
+				t.Errorf("note, reason = %q, %q, want %q, %q", note, reason, tt.note, tt.reason)
+			}
+			if reason == "" && !reflect.DeepEqual(segmentTimes(shift, breaks), tt.segments) {
+				t.Errorf("segments = %q, want %q", segmentTimes(shift, breaks), tt.segments)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package factorial
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Recovery decides what happens when a shift with breaks fails half way,
+// which would otherwise leave a half-open shift behind
+type Recovery string
+
+const (
+	// RecoveryRollback deletes the segments created for the day
+	RecoveryRollback Recovery = "rollback"
+	// RecoveryResume retries from the first step Factorial didn't record and
+	// rolls back if that fails too
+	RecoveryResume Recovery = "resume"
+)
+
+// ParseRecovery validates a recovery name
+func ParseRecovery(s string) (Recovery, error) {
+	switch r := Recovery(s); r {
+	case RecoveryRollback, RecoveryResume:
+		return r, nil
+	}
+	return "", fmt.Errorf("invalid recovery %q, expected %s or %s", s, RecoveryRollback, RecoveryResume)
+}
+
+// breakStep is one of the requests recording a shift with breaks
+type breakStep struct {
+	endpoint string
+	at       string
+	send     func(context.Context, BreakShift) error
+	// location is sent when clocking in, and when ending a break if the next
+	// segment is worked from elsewhere
+	location string
+}
+
+// breakSteps lists the requests recording a shift with breaks in order. Even
+// steps open a segment and odd steps close it.
+func (c *Client) breakSteps(shift NewShift, breaks []Segment) []breakStep {
+	steps := []breakStep{{"/clock_in", shift.ClockIn, c.ClockInAt, shift.LocationType}}
+	previous := shift.LocationType
+	for i, b := range breaks {
+		location := ""
+		if i+1 < len(shift.Locations) && shift.Locations[i+1] != previous {
+			location = shift.Locations[i+1]
+			previous = location
+		}
+		steps = append(steps,
+			breakStep{"/break_start", b.Start, c.BreakStartAt, ""},
+			breakStep{"/break_end", b.End, c.BreakEndAt, location},
+		)
+	}
+	return append(steps, breakStep{"/clock_out", shift.ClockOut, c.ClockOutAt, ""})
+}
+
+// stepEvent returns the payload of a step of the shift
+func (c *Client) stepEvent(shift NewShift, step breakStep) BreakShift {
+	return BreakShift{
+		EmployeeId:   shift.EmployeeId,
+		LocationType: step.location,
+		Now:          c.timestamp(shift.Date, step.at),
+	}
+}
+
+func (c *Client) runSteps(ctx context.Context, shift NewShift, steps []breakStep) error {
+	for _, step := range steps {
+		event := c.stepEvent(shift, step)
+		if err := step.send(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addShiftWithBreak adds a shift with break times. If a step fails, the
+// segments already recorded are rolled back, or the remaining steps retried
+// with RecoveryResume. The returned note says what was done. Factorial only
+// keeps one open shift per employee, so the shifts with breaks of an employee
+// are recorded one at a time, even by concurrent callers.
+func (c *Client) addShiftWithBreak(ctx context.Context, m *Month, shift NewShift, breaks []Segment, recovery Recovery) (string, error) {
+	lock := c.clockingLock(shift.EmployeeId)
+	lock.Lock()
+	defer lock.Unlock()
+
+	steps := c.breakSteps(shift, breaks)
+	err := c.runSteps(ctx, shift, steps)
+	if err == nil {
+		return "", nil
+	}
+
+	// A step may have gone through even if its response was lost, so check
+	// what Factorial recorded rather than trusting the failed step
+	created, next, stateErr := c.dayProgress(ctx, m, shift.Day)
+	if stateErr != nil {
+		return fmt.Sprintf("couldn't check the recorded segments, the day may need manual cleanup: %v", stateErr), err
+	}
+	if next >= len(steps) {
+		return "all segments were recorded despite the error", nil
+	}
+	if len(created) == 0 && recovery != RecoveryResume {
+		return "", err
+	}
+
+	if recovery == RecoveryResume {
+		resumeErr := c.runSteps(ctx, shift, steps[next:])
+		if resumeErr == nil {
+			return fmt.Sprintf("resumed from %s", steps[next].endpoint), nil
+		}
+		err = resumeErr
+		if created, _, stateErr = c.dayProgress(ctx, m, shift.Day); stateErr != nil {
+			return fmt.Sprintf("resume failed and the recorded segments couldn't be checked, the day may need manual cleanup: %v", stateErr), err
+		}
+		if len(created) == 0 {
+			return "resume failed, nothing to roll back", err
+		}
+	}
+
+	for i, s := range created {
+		if delErr := c.DeleteShift(ctx, s.Id); delErr != nil {
+			return fmt.Sprintf("rollback failed, %d segment(s) left: %v", len(created)-i, delErr), err
+		}
+	}
+	return fmt.Sprintf("rolled back %d segment(s)", len(created)), err
+}
+
+// clockingLock returns the lock held while a shift with breaks of the
+// employee is recorded
+func (c *Client) clockingLock(employee int) *sync.Mutex {
+	c.clockingMu.Lock()
+	defer c.clockingMu.Unlock()
+	if c.clocking == nil {
+		c.clocking = map[int]*sync.Mutex{}
+	}
+	if c.clocking[employee] == nil {
+		c.clocking[employee] = &sync.Mutex{}
+	}
+	return c.clocking[employee]
+}
+
+// dayProgress returns the segments recorded on a day since the month was
+// loaded and the index of the first break step still to be done
+func (c *Client) dayProgress(ctx context.Context, m *Month, day int) ([]Shift, int, error) {
+	shifts, err := c.Shifts(ctx, m.EmployeeId, m.Year, m.Month)
+	if err != nil {
+		return nil, 0, err
+	}
+	existing := map[int64]bool{}
+	for _, s := range m.Shifts {
+		existing[s.Id] = true
+	}
+
+	var created []Shift
+	next := 0
+	for _, s := range shifts {
+		if s.Day != day || existing[s.Id] {
+			continue
+		}
+		created = append(created, s)
+		if s.ClockOut == "" {
+			next++
+		} else {
+			next += 2
+		}
+	}
+	sort.Slice(created, func(i, j int) bool {
+		return created[i].ClockIn < created[j].ClockIn
+	})
+	return created, next, nil
+}
@@ -0,0 +1,57 @@
+package factorial_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alejoar/factorialsucks/factorial"
+)
+
+func TestClockInRecovery(t *testing.T) {
+	tests := []struct {
+		name     string
+		recovery factorial.Recovery
+		// failures is how many times /break_end fails
+		failures int
+		fails    bool
+		note     string
+		shifts   []string
+	}{
+		{"rollback", factorial.RecoveryRollback, 1, true, "rolled back 1 segment(s)", nil},
+		{"resume", factorial.RecoveryResume, 1, false, "resumed from /break_end", []string{"08:45 - 14:30", "15:00 - 17:30"}},
+		{"resume failing again", factorial.RecoveryResume, 2, true, "rolled back 1 segment(s)", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t)
+			c := login(t, s)
+			s.Fail("POST", "/api/2025-10-01/resources/attendance/shifts/break_end", tt.failures, http.StatusUnprocessableEntity, `{"error":"Something went wrong"}`)
+
+			var result factorial.DayResult
+			err := c.ClockIn(context.Background(), loadMonth(t, c), factorial.ClockInOptions{
+				TodayOnly: true,
+				Now:       time.Date(2026, 10, 6, 12, 0, 0, 0, time.Local),
+				Recovery:  tt.recovery,
+			}, func(r factorial.DayResult) {
+				if !r.Skipped {
+					result = r
+				}
+			})
+			if (err != nil) != tt.fails || (result.Err != nil) != tt.fails {
+				t.Errorf("ClockIn = %v, day error %v, want failed %v", err, result.Err, tt.fails)
+			}
+			if result.Recovery != tt.note {
+				t.Errorf("recovery = %q, want %q", result.Recovery, tt.note)
+			}
+			var got []string
+			for _, shift := range s.Shifts("2026-10-06") {
+				got = append(got, shift.ClockIn+" - "+shift.ClockOut)
+			}
+			if !equalStrings(got, tt.shifts) {
+				t.Errorf("shifts left = %q, want %q", got, tt.shifts)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package factorial
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRecurrenceDays bounds how far a recurring event is expanded, so a rule
+// with a far away end doesn't loop for ever
+const maxRecurrenceDays = 100 * 366
+
+// rrule is the part of an iCalendar RRULE ReadICS understands: daily and
+// weekly rules with INTERVAL, COUNT, UNTIL, BYDAY and WKST
+type rrule struct {
+	freq     string
+	interval int
+	count    int
+	// until is the last time an occurrence can start, zero when the rule
+	// has no end
+	until time.Time
+	byDay []time.Weekday
+	wkst  time.Weekday
+}
+
+var icsWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// parseRRule parses the RRULE of an event starting at start, UNTIL dates
+// without a time zone are in start's
+func parseRRule(value string, start time.Time) (rrule, error) {
+	r := rrule{interval: 1, wkst: time.Monday}
+	for _, part := range strings.Split(value, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return r, fmt.Errorf("invalid rule part %q", part)
+		}
+		name, v := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		var err error
+		switch name {
+		case "FREQ":
+			if v != "DAILY" && v != "WEEKLY" {
+				return r, fmt.Errorf("FREQ=%s is not supported, only DAILY and WEEKLY", v)
+			}
+			r.freq = v
+		case "INTERVAL":
+			if r.interval, err = strconv.Atoi(v); err != nil || r.interval < 1 {
+				return r, fmt.Errorf("invalid INTERVAL %q", v)
+			}
+		case "COUNT":
+			if r.count, err = strconv.Atoi(v); err != nil || r.count < 1 {
+				return r, fmt.Errorf("invalid COUNT %q", v)
+			}
+		case "UNTIL":
+			if r.until, err = parseUntil(v, start.Location()); err != nil {
+				return r, err
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(v, ",") {
+				wd, ok := icsWeekdays[d]
+				if !ok {
+					return r, fmt.Errorf("BYDAY=%s is not supported, only weekdays without a position", d)
+				}
+				r.byDay = append(r.byDay, wd)
+			}
+		case "WKST":
+			wd, ok := icsWeekdays[v]
+			if !ok {
+				return r, fmt.Errorf("invalid WKST %q", v)
+			}
+			r.wkst = wd
+		default:
+			return r, fmt.Errorf("%s is not supported", name)
+		}
+	}
+	if r.freq == "" {
+		return r, fmt.Errorf("FREQ is missing")
+	}
+	return r, nil
+}
+
+// parseUntil parses an UNTIL date or date-time, a date lasts until its end
+func parseUntil(value string, loc *time.Location) (time.Time, error) {
+	if len(value) == len("20060102") {
+		d, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return d, fmt.Errorf("invalid UNTIL %q", value)
+		}
+		return d.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	t, err := parseICSTime(value, "", loc)
+	if err != nil {
+		return t, fmt.Errorf("invalid UNTIL %q", value)
+	}
+	return t, nil
+}
+
+// starts returns the start of every occurrence of the rule for an event
+// starting at start, which is always the first one. Occurrences start at the
+// same wall clock time as start. Rules without an end stop before horizon.
+func (r rrule) starts(start, horizon time.Time) []time.Time {
+	limit := r.until
+	if limit.IsZero() && r.count == 0 {
+		limit = horizon.Add(-time.Nanosecond)
+	}
+	byDay := r.byDay
+	if r.freq == "WEEKLY" && len(byDay) == 0 {
+		byDay = []time.Weekday{start.Weekday()}
+	}
+	first := dayNumber(start)
+	firstWeek := first - weekOffset(start.Weekday(), r.wkst)
+
+	starts := []time.Time{start}
+	for i := 1; i <= maxRecurrenceDays; i++ {
+		if r.count > 0 && len(starts) >= r.count {
+			break
+		}
+		t := time.Date(start.Year(), start.Month(), start.Day()+i, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+		if !limit.IsZero() && t.After(limit) {
+			break
+		}
+		if len(byDay) > 0 && !containsWeekday(byDay, t.Weekday()) {
+			continue
+		}
+		day := dayNumber(t)
+		switch r.freq {
+		case "DAILY":
+			if (day-first)%r.interval != 0 {
+				continue
+			}
+		case "WEEKLY":
+			week := (day - weekOffset(t.Weekday(), r.wkst) - firstWeek) / 7
+			if week%r.interval != 0 {
+				continue
+			}
+		}
+		starts = append(starts, t)
+	}
+	return starts
+}
+
+// dayNumber counts the days from the epoch to the date of t, ignoring its
+// time zone's offset
+func dayNumber(t time.Time) int {
+	return int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+// weekOffset returns how many days after the start of the week wd is
+func weekOffset(wd, wkst time.Weekday) int {
+	return (int(wd) - int(wkst) + 7) % 7
+}
+
+func containsWeekday(days []time.Weekday, wd time.Weekday) bool {
+	for _, d := range days {
+		if d == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// occurrences returns the start of every occurrence of an event: its start,
+// and for recurring events the ones of its RRULE and RDATEs that aren't
+// excluded by an EXDATE or replaced by another event with the same UID and a
+// RECURRENCE-ID. Rules without an end are expanded until horizon.
+func (e icsEvent) occurrences(horizon time.Time, overridden map[string]bool) ([]time.Time, error) {
+	starts := []time.Time{e.start}
+	if e.rrule != "" {
+		r, err := parseRRule(e.rrule, e.start)
+		if err != nil {
+			return nil, fmt.Errorf("recurring event %q on %s: %v", e.summary, e.start.Format("2006-01-02 15:04"), err)
+		}
+		starts = r.starts(e.start, horizon)
+	}
+	starts = append(starts, e.rdates...)
+
+	var kept []time.Time
+	seen := map[int64]bool{}
+	for _, t := range starts {
+		day := t.Format("2006-01-02")
+		if seen[t.Unix()] || e.exdays[day] || overridden[occurrenceKey(e.uid, t)] || overridden[e.uid+"|"+day] {
+			continue
+		}
+		excluded := false
+		for _, ex := range e.exdates {
+			if ex.Equal(t) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			seen[t.Unix()] = true
+			kept = append(kept, t)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].Before(kept[j]) })
+	return kept, nil
+}
+
+// occurrenceKey identifies an occurrence of a recurring event
+func occurrenceKey(uid string, t time.Time) string {
+	return uid + "|" + t.UTC().Format("20060102T150405Z")
+}
@@ -0,0 +1,180 @@
+package factorial
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ResetResult is the outcome of deleting a single shift
+type ResetResult struct {
+	Date  time.Time
+	Shift Shift
+	Err   error
+}
+
+// ShiftFilter selects the shifts a reset deletes. Empty fields match every
+// shift.
+type ShiftFilter struct {
+	Weekdays []time.Weekday
+	// Sources are compared case insensitively, e.g. desktop or manual
+	Sources []string
+	// Window keeps the shifts that start and end within it. Open shifts end
+	// at midnight.
+	Window *Segment
+}
+
+// ParseShiftFilter builds a filter from weekday names (monday or mon),
+// sources and a HH:MM-HH:MM window, any of them can be empty. Weekdays and
+// sources may be comma separated lists. The window can end at 24:00 to take
+// in open shifts.
+func ParseShiftFilter(weekdays, sources []string, window string) (ShiftFilter, error) {
+	var f ShiftFilter
+	for _, name := range splitList(weekdays) {
+		d, err := parseWeekday(name)
+		if err != nil {
+			return f, err
+		}
+		f.Weekdays = append(f.Weekdays, d)
+	}
+	f.Sources = splitList(sources)
+	if window != "" {
+		parts := strings.Split(window, "-")
+		if len(parts) != 2 {
+			return f, fmt.Errorf("invalid window %q, use HH:MM-HH:MM", window)
+		}
+		start, end := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		from, errStart := parseClock(start)
+		to, errEnd := windowEnd(end)
+		if errStart != nil || errEnd != nil || to <= from {
+			return f, fmt.Errorf("invalid window %q, use HH:MM-HH:MM", window)
+		}
+		f.Window = &Segment{Start: start, End: end}
+	}
+	return f, nil
+}
+
+// splitList splits comma separated items, dropping the empty ones
+func splitList(values []string) []string {
+	var list []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// windowEnd parses the end of a window, which may be 24:00
+func windowEnd(s string) (int, error) {
+	if s == "24:00" {
+		return dayMinutes, nil
+	}
+	return parseClock(s)
+}
+
+// Match reports whether the filter selects a shift recorded on date
+func (f ShiftFilter) Match(date time.Time, s Shift) bool {
+	if len(f.Weekdays) > 0 {
+		found := false
+		for _, d := range f.Weekdays {
+			if d == date.Weekday() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Sources) > 0 {
+		found := false
+		for _, source := range f.Sources {
+			if strings.EqualFold(source, s.Source) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Window != nil {
+		start, _ := parseClock(f.Window.Start)
+		end, _ := windowEnd(f.Window.End)
+		in, err := parseClock(s.ClockIn)
+		if err != nil || in < start {
+			return false
+		}
+		out := dayMinutes
+		if s.ClockOut != "" {
+			if out, err = parseClock(s.ClockOut); err != nil {
+				return false
+			}
+		}
+		if out > end {
+			return false
+		}
+	}
+	return true
+}
+
+// FindShifts returns the shifts of the month within its range the filter
+// selects
+func (m *Month) FindShifts(f ShiftFilter) []Shift {
+	var shifts []Shift
+	for _, shift := range m.Shifts {
+		if m.InRange(shift.Day) && f.Match(m.Date(shift.Day), shift) {
+			shifts = append(shifts, shift)
+		}
+	}
+	return shifts
+}
+
+// ResetMonth deletes all shifts of the month within its range, calling report
+// with the result of each deletion. Failed deletions are returned together in
+// a *RunError.
+func (c *Client) ResetMonth(ctx context.Context, m *Month, report func(ResetResult)) error {
+	return c.DeleteShifts(ctx, m, m.FindShifts(ShiftFilter{}), report)
+}
+
+// ResetMonths deletes the shifts of the months the filter selects in order as
+// a single run, the deletions that fail in any of them are returned together
+// in a *RunError
+func (c *Client) ResetMonths(ctx context.Context, months []*Month, f ShiftFilter, report func(ResetResult)) error {
+	var failed []*DayError
+	for _, m := range months {
+		err := c.DeleteShifts(ctx, m, m.FindShifts(f), report)
+		if failed, err = collectDayErrors(failed, err); err != nil {
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		return &RunError{Errors: failed}
+	}
+	return nil
+}
+
+// DeleteShifts deletes shifts of the month, calling report with the result of
+// each deletion. Failed deletions are returned together in a *RunError.
+func (c *Client) DeleteShifts(ctx context.Context, m *Month, shifts []Shift, report func(ResetResult)) error {
+	var failed []*DayError
+	for _, shift := range shifts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result := ResetResult{Date: m.Date(shift.Day), Shift: shift}
+		result.Err = c.DeleteShift(ctx, shift.Id)
+		if result.Err != nil {
+			failed = append(failed, &DayError{Date: result.Date, Err: result.Err})
+		}
+		report(result)
+	}
+	if len(failed) > 0 {
+		return &RunError{Errors: failed}
+	}
+	return nil
+}
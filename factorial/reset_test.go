@@ -0,0 +1,88 @@
+package factorial
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseShiftFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		weekdays []string
+		sources  []string
+		window   string
+		want     ShiftFilter
+		err      bool
+	}{
+		{name: "empty", want: ShiftFilter{}},
+		{
+			name:     "comma lists next to repeated flags",
+			weekdays: []string{"mon,fri", "Wednesday", " tue , "},
+			sources:  []string{"manual, desktop", "approved"},
+			want: ShiftFilter{
+				Weekdays: []time.Weekday{time.Monday, time.Friday, time.Wednesday, time.Tuesday},
+				Sources:  []string{"manual", "desktop", "approved"},
+			},
+		},
+		{name: "window", window: "09:00-13:00", want: ShiftFilter{Window: &Segment{Start: "09:00", End: "13:00"}}},
+		{name: "window with spaces", window: "09:00 - 13:00", want: ShiftFilter{Window: &Segment{Start: "09:00", End: "13:00"}}},
+		{name: "window until midnight", window: "18:00-24:00", want: ShiftFilter{Window: &Segment{Start: "18:00", End: "24:00"}}},
+		{name: "invalid weekday", weekdays: []string{"mon,funday"}, err: true},
+		{name: "reversed window", window: "13:00-09:00", err: true},
+		{name: "empty window", window: "09:00-09:00", err: true},
+		{name: "window without end", window: "09:00", err: true},
+		{name: "window starting at 24:00", window: "24:00-24:00", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseShiftFilter(tt.weekdays, tt.sources, tt.window)
+			if (err != nil) != tt.err {
+				t.Fatalf("ParseShiftFilter error = %v, want error %v", err, tt.err)
+			}
+			if !tt.err && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseShiftFilter = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShiftFilterMatch(t *testing.T) {
+	monday := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	manual := Shift{ClockIn: "09:00", ClockOut: "13:00", Source: "Manual"}
+	open := Shift{ClockIn: "18:00", Source: "desktop"}
+	tests := []struct {
+		name     string
+		weekdays []string
+		sources  []string
+		window   string
+		shift    Shift
+		want     bool
+	}{
+		{"no filter", nil, nil, "", manual, true},
+		{"no filter open shift", nil, nil, "", open, true},
+		{"weekday", []string{"fri,mon"}, nil, "", manual, true},
+		{"other weekday", []string{"tue", "fri"}, nil, "", manual, false},
+		{"source ignoring case", nil, []string{"desktop,manual"}, "", manual, true},
+		{"other source", nil, []string{"desktop"}, "", manual, false},
+		{"weekday and other source", []string{"mon"}, []string{"desktop"}, "", manual, false},
+		{"window touching both edges", nil, nil, "09:00-13:00", manual, true},
+		{"window starting after the clock in", nil, nil, "09:01-13:00", manual, false},
+		{"window ending before the clock out", nil, nil, "09:00-12:59", manual, false},
+		{"window around", nil, nil, "08:00-14:00", manual, true},
+		{"open shift in a window before midnight", nil, nil, "17:00-23:59", open, false},
+		{"open shift in a window until midnight", nil, nil, "18:00-24:00", open, true},
+		{"open shift starting before the window", nil, nil, "18:01-24:00", open, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseShiftFilter(tt.weekdays, tt.sources, tt.window)
+			if err != nil {
+				t.Fatalf("ParseShiftFilter: %v", err)
+			}
+			if got := f.Match(monday, tt.shift); got != tt.want {
+				t.Errorf("Match(%s - %s) = %v, want %v", tt.shift.ClockIn, tt.shift.ClockOut, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package factorial
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RestoreResult is the outcome of re-creating a single shift of a backup
+type RestoreResult struct {
+	Date    time.Time
+	Shift   BackupShift
+	Skipped bool
+	Reason  string
+	Err     error
+}
+
+// Range returns the days the shifts of the backup cover
+func (b *Backup) Range() (DateRange, error) {
+	var r DateRange
+	for _, s := range b.Shifts {
+		date, err := time.Parse("2006-01-02", s.Date)
+		if err != nil {
+			return r, fmt.Errorf("invalid shift date %q, use YYYY-MM-DD", s.Date)
+		}
+		if r.From.IsZero() || date.Before(r.From) {
+			r.From = date
+		}
+		if date.After(r.To) {
+			r.To = date
+		}
+	}
+	return r, nil
+}
+
+// RestoreOptions configures a restore
+type RestoreOptions struct {
+	// Force restores a backup of another employee into the account logged in
+	Force bool
+}
+
+// Restore re-creates the shifts of a backup in date order, calling report
+// with the result of each one. months must cover the backup's range, see
+// LoadRange. Shifts overlapping a recorded shift are skipped, so restoring
+// twice doesn't duplicate them. Failed shifts are returned together in a
+// *RunError. Backups of another employee are an *EmployeeMismatchError
+// unless forced.
+func (c *Client) Restore(ctx context.Context, months []*Month, b *Backup, opts RestoreOptions, report func(RestoreResult)) error {
+	if len(months) > 0 && b.EmployeeId != 0 && b.EmployeeId != months[0].EmployeeId && !opts.Force {
+		return &EmployeeMismatchError{Backup: b.EmployeeId, Employee: months[0].EmployeeId}
+	}
+	shifts := append([]BackupShift(nil), b.Shifts...)
+	sortShifts(shifts)
+
+	var failed []*DayError
+	for _, s := range shifts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result := RestoreResult{Shift: s}
+		result.Date, result.Err = time.Parse("2006-01-02", s.Date)
+		if result.Err != nil {
+			result.Err = fmt.Errorf("invalid shift date %q, use YYYY-MM-DD", s.Date)
+			failed = append(failed, &DayError{Date: result.Date, Err: result.Err})
+			report(result)
+			continue
+		}
+		m := findMonth(months, result.Date)
+		if m == nil {
+			return fmt.Errorf("%s is not in the months loaded", s.Date)
+		}
+
+		shift := m.recreatedShift(result.Date.Day(), s.Shift)
+		if s.ClockOut == "" {
+			result.Skipped, result.Reason = true, "Open shift"
+		} else if found := m.overlapping(shift.Day, plannedSpans(shift, nil)); len(found) > 0 {
+			result.Skipped, result.Reason = true, fmt.Sprintf("Period overlap: %s", describeOverlap(found))
+		} else if result.Err = c.CreateShift(ctx, shift); result.Err != nil {
+			failed = append(failed, &DayError{Date: result.Date, Err: result.Err})
+		} else {
+			// Later shifts of the backup are checked against this one too
+			m.Shifts = append(m.Shifts, Shift{
+				Day:          shift.Day,
+				ClockIn:      s.ClockIn,
+				ClockOut:     s.ClockOut,
+				LocationType: s.LocationType,
+				Source:       s.Source,
+			})
+		}
+		report(result)
+	}
+	if len(failed) > 0 {
+		return &RunError{Errors: failed}
+	}
+	return nil
+}
+
+// recreatedShift returns the payload creating a shift of a backup again on a
+// day of the month
+func (m *Month) recreatedShift(day int, s Shift) NewShift {
+	date := m.Date(day).Format("2006-01-02")
+	shift := NewShift{
+		ClockIn:                          s.ClockIn,
+		ClockOut:                         s.ClockOut,
+		Day:                              day,
+		EmployeeId:                       m.EmployeeId,
+		Workable:                         true,
+		LocationType:                     s.LocationType,
+		Source:                           s.Source,
+		TimeSettingsBreakConfigurationId: nil,
+		Minutes:                          nil,
+		Date:                             date,
+		ReferenceDate:                    date,
+	}
+	if shift.LocationType == "" {
+		shift.LocationType = "work_from_home"
+	}
+	if shift.Source == "" {
+		shift.Source = "desktop"
+	}
+	return shift
+}
+
+// findMonth returns the month of date, nil if it's not in months
+func findMonth(months []*Month, date time.Time) *Month {
+	for _, m := range months {
+		if m.Year == date.Year() && m.Month == int(date.Month()) {
+			return m
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package factorial_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alejoar/factorialsucks/factorial"
+)
+
+// restore restores a backup into October 2026, returning what was restored
+// and skipped
+func restore(t *testing.T, c *factorial.Client, b *factorial.Backup, opts factorial.RestoreOptions) ([]string, []string, error) {
+	t.Helper()
+	var restored, skipped []string
+	err := c.Restore(context.Background(), []*factorial.Month{loadMonth(t, c)}, b, opts, func(r factorial.RestoreResult) {
+		shift := r.Shift.Date + " " + r.Shift.ClockIn + " - " + r.Shift.ClockOut
+		switch {
+		case r.Skipped:
+			skipped = append(skipped, shift+": "+r.Reason)
+		case r.Err != nil:
+			t.Errorf("%s: %v", shift, r.Err)
+		default:
+			restored = append(restored, shift)
+		}
+	})
+	return restored, skipped, err
+}
+
+func TestExportRestore(t *testing.T) {
+	s := newServer(t)
+	s.AddShift("2026-10-05", "09:00", "13:00")
+	s.AddShift("2026-10-05", "14:00", "18:00")
+	s.AddShift("2026-10-06", "09:00", "17:00")
+	c := login(t, s)
+	ctx := context.Background()
+
+	// Export the month as JSON, as the export command does
+	m := loadMonth(t, c)
+	export := c.NewBackup()
+	export.Add(m, m.FindShifts(factorial.ShiftFilter{}))
+	data, err := json.Marshal(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "export.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	backup, err := factorial.LoadBackup(path)
+	if err != nil {
+		t.Fatalf("LoadBackup: %v", err)
+	}
+	// An open shift can't be restored
+	backup.Shifts = append(backup.Shifts, factorial.BackupShift{Date: "2026-10-07", Shift: factorial.Shift{ClockIn: "09:00"}})
+
+	if err := c.ResetMonth(ctx, m, func(factorial.ResetResult) {}); err != nil {
+		t.Fatalf("ResetMonth: %v", err)
+	}
+	// Recorded since the export, overlapping a shift of the backup
+	s.AddShift("2026-10-06", "16:00", "19:00")
+
+	restored, skipped, err := restore(t, c, backup, factorial.RestoreOptions{})
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if want := []string{"2026-10-05 09:00 - 13:00", "2026-10-05 14:00 - 18:00"}; !equalStrings(restored, want) {
+		t.Errorf("restored %q, want %q", restored, want)
+	}
+	want := []string{
+		"2026-10-06 09:00 - 17:00: Period overlap: 16:00 - 19:00",
+		"2026-10-07 09:00 - : Open shift",
+	}
+	if !equalStrings(skipped, want) {
+		t.Errorf("skipped %q, want %q", skipped, want)
+	}
+
+	// Restoring again doesn't duplicate anything
+	restored, _, err = restore(t, c, backup, factorial.RestoreOptions{})
+	if err != nil || len(restored) != 0 {
+		t.Errorf("Restore again = %q, %v, want nothing restored", restored, err)
+	}
+	if n := len(s.Shifts("")); n != 3 {
+		t.Errorf("stored %d shifts, want 3", n)
+	}
+}
+
+func TestRestoreOtherEmployee(t *testing.T) {
+	s := newServer(t)
+	c := login(t, s)
+	backup := &factorial.Backup{
+		EmployeeId: s.EmployeeId + 1,
+		Shifts:     []factorial.BackupShift{{Date: "2026-10-05", Shift: factorial.Shift{ClockIn: "09:00", ClockOut: "13:00"}}},
+	}
+
+	_, _, err := restore(t, c, backup, factorial.RestoreOptions{})
+	var mismatch *factorial.EmployeeMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("Restore = %v, want an *EmployeeMismatchError", err)
+	}
+	if n := len(s.Shifts("")); n != 0 {
+		t.Errorf("stored %d shifts, want none", n)
+	}
+
+	restored, _, err := restore(t, c, backup, factorial.RestoreOptions{Force: true})
+	if err != nil || len(restored) != 1 {
+		t.Errorf("forced Restore = %q, %v, want the shift restored", restored, err)
+	}
+}
@@ -0,0 +1,209 @@
+package factorial
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Retry defaults
+const (
+	DefaultMaxRetries = 3
+	DefaultMinBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+	// DefaultMaxRetryAfter is the longest Retry-After honored, longer waits
+	// give up and return the response
+	DefaultMaxRetryAfter = 2 * time.Minute
+)
+
+// RetryTransport is an http.RoundTripper that retries failed requests with
+// jittered exponential backoff, honoring Retry-After. Idempotent requests
+// (GET, HEAD, OPTIONS, PUT, DELETE or with an Idempotency-Key header) are
+// retried on network errors and 429, 502, 503 and 504 responses. Other
+// requests may have been applied despite a 5xx response, so they're only
+// retried on 429, which rejects them before they're processed. Every attempt
+// waits for the rate limiter, if any.
+type RetryTransport struct {
+	// Base sends the requests, http.DefaultTransport when nil
+	Base          http.RoundTripper
+	MaxRetries    int
+	MinBackoff    time.Duration
+	MaxBackoff    time.Duration
+	MaxRetryAfter time.Duration
+	Limiter       *RateLimiter
+}
+
+// NewRetryTransport creates a transport with the default backoff, limited to
+// requestsPerSecond (unlimited when 0)
+func NewRetryTransport(maxRetries int, requestsPerSecond float64) *RetryTransport {
+	return &RetryTransport{
+		MaxRetries:    maxRetries,
+		MinBackoff:    DefaultMinBackoff,
+		MaxBackoff:    DefaultMaxBackoff,
+		MaxRetryAfter: DefaultMaxRetryAfter,
+		Limiter:       NewRateLimiter(requestsPerSecond),
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if err := t.Limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		r := req
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		resp, err := base.RoundTrip(r)
+		if attempt >= t.MaxRetries || !t.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				if after > t.maxRetryAfter() {
+					return resp, err
+				}
+				wait = after
+			}
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *RetryTransport) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Body != nil && req.GetBody == nil {
+		// The body can't be sent again
+		return false
+	}
+	if req.Context().Err() != nil {
+		return false
+	}
+	idempotent := isIdempotent(req)
+	if err != nil {
+		return idempotent
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	_, hasKey := req.Header["Idempotency-Key"]
+	return hasKey
+}
+
+// backoff returns a random wait between 0 and the exponential backoff of the
+// attempt ("full jitter")
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	lo, hi := t.MinBackoff, t.MaxBackoff
+	if lo <= 0 {
+		lo = DefaultMinBackoff
+	}
+	if hi <= 0 {
+		hi = DefaultMaxBackoff
+	}
+	d := lo << uint(attempt)
+	if d > hi || d <= 0 {
+		d = hi
+	}
+	return time.Duration(rand.Int63n(int64(d)) + 1)
+}
+
+func (t *RetryTransport) maxRetryAfter() time.Duration {
+	if t.MaxRetryAfter <= 0 {
+		return DefaultMaxRetryAfter
+	}
+	return t.MaxRetryAfter
+}
+
+// retryAfter parses the Retry-After header, in seconds or as an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		d := time.Until(at)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RateLimiter spaces requests evenly. It's safe for concurrent use, and a
+// nil limiter doesn't limit.
+type RateLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+// NewRateLimiter allows requestsPerSecond requests per second, it returns nil
+// (no limit) when requestsPerSecond isn't positive
+func NewRateLimiter(requestsPerSecond float64) *RateLimiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	return &RateLimiter{interval: time.Duration(float64(time.Second) / requestsPerSecond)}
+}
+
+// Wait blocks until the next request is allowed
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+	return sleep(ctx, time.Until(at))
+}
@@ -0,0 +1,202 @@
+package factorial
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc sends requests with a function
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// respond returns a response with status and headers
+func respond(status int, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{StatusCode: status, Header: header, Body: ioutil.NopCloser(strings.NewReader(""))}
+}
+
+// testTransport retries once without waiting, counting the attempts
+func testTransport(send func(attempt int) (*http.Response, error)) (*RetryTransport, *int) {
+	attempts := 0
+	return &RetryTransport{
+		Base: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			attempts++
+			return send(attempts)
+		}),
+		MaxRetries: 1,
+		MinBackoff: time.Nanosecond,
+		MaxBackoff: time.Nanosecond,
+	}, &attempts
+}
+
+func newRequest(t *testing.T, method string) *http.Request {
+	t.Helper()
+	var body io.Reader
+	if method == "POST" {
+		body = strings.NewReader(`{}`)
+	}
+	req, err := http.NewRequest(method, "http://factorial.test/attendance/shifts", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestRetryTransportMatrix(t *testing.T) {
+	networkErr := errors.New("connection reset")
+	tests := []struct {
+		status int
+		err    error
+		// retried by method, POST carrying an Idempotency-Key is POST+key
+		retried map[string]bool
+	}{
+		{status: http.StatusTooManyRequests, retried: map[string]bool{"GET": true, "DELETE": true, "POST": true, "POST+key": true}},
+		{status: http.StatusServiceUnavailable, retried: map[string]bool{"GET": true, "DELETE": true, "POST": false, "POST+key": true}},
+		{status: http.StatusBadGateway, retried: map[string]bool{"GET": true, "DELETE": true, "POST": false, "POST+key": true}},
+		{status: http.StatusGatewayTimeout, retried: map[string]bool{"GET": true, "DELETE": true, "POST": false, "POST+key": true}},
+		{status: http.StatusInternalServerError, retried: map[string]bool{"GET": false, "DELETE": false, "POST": false, "POST+key": false}},
+		{status: http.StatusUnprocessableEntity, retried: map[string]bool{"GET": false, "DELETE": false, "POST": false, "POST+key": false}},
+		{err: networkErr, retried: map[string]bool{"GET": true, "DELETE": true, "POST": false, "POST+key": true}},
+	}
+	for _, tt := range tests {
+		for _, method := range []string{"GET", "DELETE", "POST", "POST+key"} {
+			transport, attempts := testTransport(func(attempt int) (*http.Response, error) {
+				if attempt > 1 {
+					return respond(http.StatusOK, nil), nil
+				}
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return respond(tt.status, nil), nil
+			})
+			req := newRequest(t, strings.TrimSuffix(method, "+key"))
+			if method == "POST+key" {
+				req.Header.Set("Idempotency-Key", "1")
+			}
+			resp, err := transport.RoundTrip(req)
+			if resp != nil {
+				resp.Body.Close()
+			}
+			retried := *attempts == 2
+			if retried != tt.retried[method] {
+				t.Errorf("%s with %d %v: retried %v, want %v", method, tt.status, tt.err, retried, tt.retried[method])
+			}
+			if retried && (err != nil || resp.StatusCode != http.StatusOK) {
+				t.Errorf("%s with %d %v: retry = %v, %v, want 200", method, tt.status, tt.err, resp, err)
+			}
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	at := time.Now().Add(90 * time.Second).UTC().Format(http.TimeFormat)
+	tests := []struct {
+		value    string
+		min, max time.Duration
+		ok       bool
+	}{
+		{"", 0, 0, false},
+		{"7", 7 * time.Second, 7 * time.Second, true},
+		{"0", 0, 0, true},
+		{at, 88 * time.Second, 90 * time.Second, true},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0, 0, true},
+		{"soon", 0, 0, false},
+		{"-1", 0, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(respond(http.StatusTooManyRequests, http.Header{"Retry-After": {tt.value}}))
+		if ok != tt.ok || got < tt.min || got > tt.max {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v to %v, %v", tt.value, got, ok, tt.min, tt.max, tt.ok)
+		}
+	}
+}
+
+func TestRetryTransportRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		attempts   int
+		status     int
+	}{
+		{"in seconds", "1", 2, http.StatusOK},
+		{"as a date in the past", "Wed, 21 Oct 2015 07:28:00 GMT", 2, http.StatusOK},
+		{"longer than MaxRetryAfter", "120", 1, http.StatusTooManyRequests},
+		{"as a date past MaxRetryAfter", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), 1, http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, attempts := testTransport(func(attempt int) (*http.Response, error) {
+				if attempt > 1 {
+					return respond(http.StatusOK, nil), nil
+				}
+				return respond(http.StatusTooManyRequests, http.Header{"Retry-After": {tt.retryAfter}}), nil
+			})
+			transport.MaxRetryAfter = time.Minute
+			// The backoff would wait longer than the test if Retry-After
+			// was ignored
+			transport.MinBackoff, transport.MaxBackoff = time.Hour, time.Hour
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			resp, err := transport.RoundTrip(newRequest(t, "GET").WithContext(ctx))
+			if err != nil {
+				t.Fatalf("RoundTrip: %v", err)
+			}
+			resp.Body.Close()
+			if *attempts != tt.attempts || resp.StatusCode != tt.status {
+				t.Errorf("%d attempts, status %d, want %d, %d", *attempts, resp.StatusCode, tt.attempts, tt.status)
+			}
+		})
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	var nilLimiter *RateLimiter
+	if err := nilLimiter.Wait(context.Background()); err != nil {
+		t.Errorf("nil limiter: %v", err)
+	}
+	if NewRateLimiter(0) != nil {
+		t.Errorf("NewRateLimiter(0) limits")
+	}
+
+	// 50 requests per second are 20ms apart, the first one isn't delayed
+	l := NewRateLimiter(50)
+	start := time.Now()
+	var at []time.Duration
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		at = append(at, time.Since(start))
+	}
+	if at[0] > 10*time.Millisecond {
+		t.Errorf("first request waited %v", at[0])
+	}
+	for i := 1; i < len(at); i++ {
+		if gap := at[i] - at[i-1]; gap < 15*time.Millisecond {
+			t.Errorf("requests %d and %d are %v apart, want 20ms", i-1, i, gap)
+		}
+	}
+	if total := at[len(at)-1]; total < 60*time.Millisecond {
+		t.Errorf("4 requests took %v, want at least 60ms", total)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l = NewRateLimiter(0.1)
+	l.Wait(context.Background())
+	if err := l.Wait(ctx); err != context.Canceled {
+		t.Errorf("Wait with a cancelled context = %v", err)
+	}
+}
@@ -0,0 +1,355 @@
+package factorial
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schedule is an ordered list of rules, the first matching rule decides the
+// shift generated for a day
+type Schedule struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+	// Locations decide where the shifts are worked from, the first matching
+	// rule wins
+	Locations []LocationRule `yaml:"locations,omitempty" json:"locations,omitempty"`
+}
+
+// Rule describes which days it applies to and the shift to create for them.
+// Unset matchers match every day.
+type Rule struct {
+	Name             string    `yaml:"name,omitempty" json:"name,omitempty"`
+	Weekdays         []string  `yaml:"weekdays,omitempty" json:"weekdays,omitempty"`
+	Dates            *DateSpan `yaml:"dates,omitempty" json:"dates,omitempty"`
+	DayBeforeHoliday *bool     `yaml:"day_before_holiday,omitempty" json:"day_before_holiday,omitempty"`
+	IsLeave          *bool     `yaml:"is_leave,omitempty" json:"is_leave,omitempty"`
+	MinutesLeft      *float64  `yaml:"minutes_left,omitempty" json:"minutes_left,omitempty"`
+	ClockIn          string    `yaml:"clock_in,omitempty" json:"clock_in,omitempty"`
+	ClockOut         string    `yaml:"clock_out,omitempty" json:"clock_out,omitempty"`
+	Breaks           []Segment `yaml:"breaks,omitempty" json:"breaks,omitempty"`
+	// Start generates the shift from the minutes Factorial expects for the
+	// day instead of ClockIn and ClockOut: it begins at Start, lasts the
+	// expected minutes and takes the breaks of BreakRules
+	Start      string      `yaml:"start,omitempty" json:"start,omitempty"`
+	BreakRules []BreakRule `yaml:"break_rules,omitempty" json:"break_rules,omitempty"`
+}
+
+// BreakRule adds a break to the shifts generated from the expected minutes of
+// days expecting at least MinMinutes. The break starts at Start, or once
+// After minutes have been worked.
+type BreakRule struct {
+	MinMinutes int    `yaml:"min_minutes,omitempty" json:"min_minutes,omitempty"`
+	Start      string `yaml:"start,omitempty" json:"start,omitempty"`
+	After      int    `yaml:"after,omitempty" json:"after,omitempty"`
+	Minutes    int    `yaml:"minutes" json:"minutes"`
+}
+
+// DateSpan is an inclusive date range. Dates are either YYYY-MM-DD or MM-DD,
+// the latter repeating every year.
+type DateSpan struct {
+	From string `yaml:"from" json:"from"`
+	To   string `yaml:"to" json:"to"`
+}
+
+// Segment is a time window within a day in HH:MM format. Worked segments
+// have the location they're worked from.
+type Segment struct {
+	Start    string `yaml:"start" json:"start"`
+	End      string `yaml:"end" json:"end"`
+	Location string `yaml:"location,omitempty" json:"location,omitempty"`
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+// lunchBreak is the break of the built-in schedule for days of 8 hours or more
+var lunchBreak = BreakRule{MinMinutes: 8 * 60, Start: "14:30", Minutes: 30}
+
+// DefaultSchedule returns the built-in rule set. Shifts last the minutes
+// Factorial expects for each day, so they fit any contract: a standard
+// 8:15 hour day is 08:45 to 17:30 with a break at 14:30, and a 7 hour
+// Friday 08:00 to 15:00.
+func DefaultSchedule() *Schedule {
+	return &Schedule{Rules: []Rule{
+		{
+			Name:  "summer",
+			Dates: &DateSpan{From: "07-01", To: "09-14"},
+			Start: "08:00",
+		},
+		{
+			Name:       "friday",
+			Weekdays:   []string{"friday"},
+			Start:      "08:00",
+			BreakRules: []BreakRule{lunchBreak},
+		},
+		{
+			Name:             "day before holiday",
+			DayBeforeHoliday: boolPtr(true),
+			Start:            "08:00",
+			BreakRules:       []BreakRule{lunchBreak},
+		},
+		{
+			Name:       "regular",
+			Start:      "08:45",
+			BreakRules: []BreakRule{lunchBreak},
+		},
+	}}
+}
+
+// LoadSchedule reads a YAML schedule rules file
+func LoadSchedule(path string) (*Schedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Schedule
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &s, nil
+}
+
+func (s *Schedule) validate() error {
+	if len(s.Rules) == 0 {
+		return errors.New("no rules defined")
+	}
+	for i, r := range s.Rules {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		for _, w := range r.Weekdays {
+			if _, err := parseWeekday(w); err != nil {
+				return fmt.Errorf("rule %s: %w", name, err)
+			}
+		}
+		if r.Dates != nil {
+			if _, _, err := parseDate(r.Dates.From); err != nil {
+				return fmt.Errorf("rule %s: %w", name, err)
+			}
+			if _, _, err := parseDate(r.Dates.To); err != nil {
+				return fmt.Errorf("rule %s: %w", name, err)
+			}
+		}
+		if r.Start != "" {
+			if err := r.validateStart(); err != nil {
+				return fmt.Errorf("rule %s: %w", name, err)
+			}
+			continue
+		}
+		if r.ClockIn == "" || r.ClockOut == "" {
+			return fmt.Errorf("rule %s: clock_in and clock_out, or start, are required", name)
+		}
+		if len(r.BreakRules) > 0 {
+			return fmt.Errorf("rule %s: break_rules need start, use breaks with clock_in and clock_out", name)
+		}
+		if err := checkTimes(r.ClockIn, r.ClockOut, r.Breaks); err != nil {
+			return fmt.Errorf("rule %s: %w", name, err)
+		}
+	}
+	for i, r := range s.Locations {
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("location #%d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// validateStart checks a rule generating its shifts from the expected minutes
+func (r Rule) validateStart() error {
+	if r.ClockIn != "" || r.ClockOut != "" || len(r.Breaks) > 0 {
+		return errors.New("start can't be used with clock_in, clock_out or breaks, use break_rules")
+	}
+	if _, err := parseClock(r.Start); err != nil {
+		return err
+	}
+	for _, b := range r.BreakRules {
+		if b.Minutes <= 0 {
+			return errors.New("break rules need minutes")
+		}
+		if (b.Start == "") == (b.After <= 0) {
+			return errors.New("break rules need either start or after")
+		}
+		if b.Start != "" {
+			if _, err := parseClock(b.Start); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// times returns the clock in, clock out and breaks of the rule for a day
+// expecting minutes. A rule with Start works the expected minutes from it,
+// taking the breaks that start after the previous one and before the work is
+// done. It doesn't apply to days without expected minutes or that would end
+// after midnight.
+func (r Rule) times(minutes float64) (string, string, []Segment, bool) {
+	if r.Start == "" {
+		return r.ClockIn, r.ClockOut, r.Breaks, true
+	}
+	expected := int(math.Round(minutes))
+	in, err := parseClock(r.Start)
+	if err != nil || expected <= 0 {
+		return "", "", nil, false
+	}
+
+	var breaks []Segment
+	paused, last := 0, in
+	for _, b := range r.BreakRules {
+		if expected < b.MinMinutes {
+			continue
+		}
+		start := in + b.After + paused
+		if b.Start != "" {
+			start, _ = parseClock(b.Start)
+		}
+		if start <= last || start-in-paused >= expected {
+			continue
+		}
+		breaks = append(breaks, Segment{Start: formatClock(start), End: formatClock(start + b.Minutes)})
+		paused += b.Minutes
+		last = start + b.Minutes
+	}
+	out := in + expected + paused
+	if out >= 24*60 {
+		return "", "", nil, false
+	}
+	return r.Start, formatClock(out), breaks, true
+}
+
+// checkTimes checks that the clock in, the breaks and the clock out are valid
+// HH:MM times in increasing order
+func checkTimes(clockIn, clockOut string, breaks []Segment) error {
+	times := []string{clockIn}
+	for _, b := range breaks {
+		times = append(times, b.Start, b.End)
+	}
+	times = append(times, clockOut)
+	last := -1
+	for _, t := range times {
+		m, err := parseClock(t)
+		if err != nil {
+			return err
+		}
+		if m <= last {
+			return fmt.Errorf("times must be increasing (%s)", t)
+		}
+		last = m
+	}
+	return nil
+}
+
+// match returns the first rule that applies to the given day
+func (s *Schedule) match(day CalendarDay, date time.Time) (Rule, bool) {
+	for _, r := range s.Rules {
+		if r.matches(day, date) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+func (r Rule) matches(day CalendarDay, date time.Time) bool {
+	if len(r.Weekdays) > 0 {
+		found := false
+		for _, w := range r.Weekdays {
+			if wd, _ := parseWeekday(w); wd == date.Weekday() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.Dates != nil && !r.Dates.contains(date) {
+		return false
+	}
+	if r.DayBeforeHoliday != nil && *r.DayBeforeHoliday != day.DayBeforeHoliday {
+		return false
+	}
+	if r.IsLeave != nil && *r.IsLeave != day.IsLeave {
+		return false
+	}
+	if r.MinutesLeft != nil && *r.MinutesLeft != day.MinutesLeft {
+		return false
+	}
+	if _, _, _, ok := r.times(day.MinutesLeft); !ok {
+		return false
+	}
+	return true
+}
+
+func (d DateSpan) contains(date time.Time) bool {
+	fromYear, from, _ := parseDate(d.From)
+	toYear, to, _ := parseDate(d.To)
+	day := int(date.Month())*100 + date.Day()
+	if fromYear != 0 || toYear != 0 {
+		if fromYear == 0 {
+			fromYear = date.Year()
+		}
+		if toYear == 0 {
+			toYear = date.Year()
+		}
+		key := date.Year()*10000 + day
+		return fromYear*10000+from <= key && key <= toYear*10000+to
+	}
+	if from <= to {
+		return from <= day && day <= to
+	}
+	// Recurring span wrapping around the new year, e.g. 12-15 to 01-15
+	return day >= from || day <= to
+}
+
+// parseDate parses YYYY-MM-DD or MM-DD, returning the year (0 when
+// recurring) and the date as MMDD
+func parseDate(s string) (int, int, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.Year(), int(t.Month())*100 + t.Day(), nil
+	}
+	if t, err := time.Parse("01-02", s); err == nil {
+		return 0, int(t.Month())*100 + t.Day(), nil
+	}
+	return 0, 0, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or MM-DD", s)
+}
+
+// parseClock parses HH:MM into minutes since midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// formatClock formats minutes since midnight as HH:MM
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// normalizeClock rewrites a valid time as HH:MM, e.g. 9:00 as 09:00
+func normalizeClock(s string) string {
+	if m, err := parseClock(s); err == nil {
+		return formatClock(m)
+	}
+	return s
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(s)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q", s)
+}
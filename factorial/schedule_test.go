@@ -0,0 +1,133 @@
+package factorial
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// ruleTimes formats the times of a rule like "08:45-17:30 break 14:30-15:00"
+func ruleTimes(in, out string, breaks []Segment, ok bool) string {
+	if !ok {
+		return "doesn't apply"
+	}
+	s := in + "-" + out
+	for _, b := range breaks {
+		s += " break " + b.Start + "-" + b.End
+	}
+	return s
+}
+
+func TestRuleTimes(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		minutes float64
+		want    string
+	}{
+		{
+			name:    "regular day",
+			rule:    Rule{Start: "08:45", BreakRules: []BreakRule{lunchBreak}},
+			minutes: 495,
+			want:    "08:45-17:30 break 14:30-15:00",
+		},
+		{
+			name:    "part-time day without the lunch break",
+			rule:    Rule{Start: "08:45", BreakRules: []BreakRule{lunchBreak}},
+			minutes: 240,
+			want:    "08:45-12:45",
+		},
+		{
+			name:    "day of a 40 hour week",
+			rule:    Rule{Start: "08:45", BreakRules: []BreakRule{lunchBreak}},
+			minutes: 480,
+			want:    "08:45-17:15 break 14:30-15:00",
+		},
+		{
+			name: "breaks after worked minutes",
+			rule: Rule{Start: "09:00", BreakRules: []BreakRule{
+				{After: 240, Minutes: 60},
+				{MinMinutes: 420, After: 360, Minutes: 15},
+			}},
+			minutes: 480,
+			want:    "09:00-18:15 break 13:00-14:00 break 16:00-16:15",
+		},
+		{
+			name: "break rule below its minimum",
+			rule: Rule{Start: "09:00", BreakRules: []BreakRule{
+				{After: 240, Minutes: 60},
+				{MinMinutes: 420, After: 360, Minutes: 15},
+			}},
+			minutes: 400,
+			want:    "09:00-16:40 break 13:00-14:00",
+		},
+		{
+			name:    "break after the work is done",
+			rule:    Rule{Start: "08:00", BreakRules: []BreakRule{{Start: "14:30", Minutes: 30}}},
+			minutes: 300,
+			want:    "08:00-13:00",
+		},
+		{
+			name:    "ending after midnight",
+			rule:    Rule{Start: "20:00", BreakRules: []BreakRule{lunchBreak}},
+			minutes: 300,
+			want:    "doesn't apply",
+		},
+		{
+			name:    "ending at midnight",
+			rule:    Rule{Start: "16:00"},
+			minutes: 480,
+			want:    "doesn't apply",
+		},
+		{
+			name:    "no expected minutes",
+			rule:    Rule{Start: "08:45"},
+			minutes: 0,
+			want:    "doesn't apply",
+		},
+		{
+			name:    "fixed times",
+			rule:    Rule{ClockIn: "09:00", ClockOut: "14:00", Breaks: []Segment{{Start: "11:00", End: "11:15"}}},
+			minutes: 0,
+			want:    "09:00-14:00 break 11:00-11:15",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ruleTimes(tt.rule.times(tt.minutes)); got != tt.want {
+				t.Errorf("times(%v) = %s, want %s", tt.minutes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultSchedule(t *testing.T) {
+	tests := []struct {
+		date             string
+		minutes          float64
+		dayBeforeHoliday bool
+		want             string
+	}{
+		{"2026-10-06", 495, false, "regular 08:45-17:30 break 14:30-15:00"},
+		{"2026-10-16", 420, false, "friday 08:00-15:00"},
+		// A regular day before a holiday starts earlier, not shorter
+		{"2026-10-07", 495, true, "day before holiday 08:00-16:45 break 14:30-15:00"},
+		// Summer days keep the expected minutes without the lunch break
+		{"2026-07-15", 495, false, "summer 08:00-16:15"},
+		{"2026-07-17", 420, false, "summer 08:00-15:00"},
+		{"2026-09-15", 495, false, "regular 08:45-17:30 break 14:30-15:00"},
+		{"2026-10-12", 0, false, "no rule"},
+	}
+	s := DefaultSchedule()
+	for _, tt := range tests {
+		date, _ := time.Parse("2006-01-02", tt.date)
+		day := CalendarDay{Date: tt.date, Day: date.Day(), IsLaborable: true, MinutesLeft: tt.minutes, DayBeforeHoliday: tt.dayBeforeHoliday}
+		got := "no rule"
+		if r, ok := s.match(day, date); ok {
+			got = fmt.Sprintf("%s %s", r.Name, ruleTimes(r.times(day.MinutesLeft)))
+		}
+		if got != tt.want {
+			t.Errorf("%s: %s, want %s", tt.date, got, tt.want)
+		}
+	}
+}
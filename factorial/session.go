@@ -0,0 +1,143 @@
+package factorial
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Session is a saved login, the cookies of the API host for an account
+type Session struct {
+	BaseUrl string          `json:"base_url"`
+	Email   string          `json:"email"`
+	Cookies []SessionCookie `json:"cookies"`
+	SavedAt time.Time       `json:"saved_at"`
+}
+
+// SessionCookie is a cookie of a saved session
+type SessionCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// DefaultSessionPath returns where sessions are saved by default, inside the
+// user's config directory
+func DefaultSessionPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "factorialsucks", "session.json"), nil
+}
+
+// LoadSession reads a session saved with Save
+func LoadSession(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Save writes the session to path, readable by the current user only
+func (s *Session) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writePrivateFile(path, data)
+}
+
+// writePrivateFile replaces the file at path with data, readable by the
+// current user only, creating its directory if needed
+func writePrivateFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Session returns the current session of the client, to be saved after Login
+func (c *Client) Session(email string) *Session {
+	s := &Session{BaseUrl: c.baseUrl(), Email: email, SavedAt: time.Now()}
+	if c.Jar == nil {
+		return s
+	}
+	u, err := url.Parse(c.baseUrl())
+	if err != nil {
+		return s
+	}
+	for _, cookie := range c.Jar.Cookies(u) {
+		s.Cookies = append(s.Cookies, SessionCookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	return s
+}
+
+// RestoreSession loads the cookies of a saved session into the client. It
+// returns false if the session belongs to another account or API, or the
+// client has no cookie jar.
+func (c *Client) RestoreSession(s *Session, email string) bool {
+	if c.Jar == nil || s.Email != email || s.BaseUrl != c.baseUrl() || len(s.Cookies) == 0 {
+		return false
+	}
+	u, err := url.Parse(c.baseUrl())
+	if err != nil {
+		return false
+	}
+	var cookies []*http.Cookie
+	for _, cookie := range s.Cookies {
+		cookies = append(cookies, &http.Cookie{Name: cookie.Name, Value: cookie.Value, Path: "/"})
+	}
+	c.Jar.SetCookies(u, cookies)
+	return true
+}
+
+// CheckSession makes a cheap authenticated request, returning an *AuthError
+// if the session has expired
+func (c *Client) CheckSession(ctx context.Context) error {
+	now := time.Now()
+	_, err := c.Periods(ctx, PeriodsQuery{Year: now.Year(), Month: int(now.Month())})
+	return err
+}
+
+// ResumeSession restores the session saved at path and checks it's still
+// valid. It returns false, to log in with the password instead, when there's
+// no session saved for the account and API or it has expired.
+func (c *Client) ResumeSession(ctx context.Context, path, email string) (bool, error) {
+	session, err := LoadSession(path)
+	if err != nil || !c.RestoreSession(session, email) {
+		return false, nil
+	}
+	err = c.CheckSession(ctx)
+	var authErr *AuthError
+	if errors.As(err, &authErr) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,98 @@
+package factorial_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alejoar/factorialsucks/factorial"
+)
+
+func TestSessionSave(t *testing.T) {
+	s := newServer(t)
+	c := login(t, s)
+	path := filepath.Join(t.TempDir(), "config", "session.json")
+
+	if err := c.Session(testEmail).Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("session mode = %o, want 600", mode)
+	}
+	// Saving again replaces the file, keeping it private
+	if err := c.Session(testEmail).Save(path); err != nil {
+		t.Fatalf("Save again: %v", err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("session mode = %o after saving again, want 600", info.Mode().Perm())
+	}
+
+	session, err := factorial.LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if session.Email != testEmail || session.BaseUrl != s.URL || len(session.Cookies) == 0 {
+		t.Errorf("session = %+v, want the cookies of %s at %s", session, testEmail, s.URL)
+	}
+}
+
+func TestResumeSession(t *testing.T) {
+	s := newServer(t)
+	other := newServer(t)
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := login(t, s).Session(testEmail).Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		client *factorial.Client
+		email  string
+		path   string
+		want   bool
+	}{
+		{"same account", s.NewClient(), testEmail, path, true},
+		{"another account", s.NewClient(), "john@example.com", path, false},
+		{"another base url", other.NewClient(), testEmail, path, false},
+		{"nothing saved", s.NewClient(), testEmail, filepath.Join(t.TempDir(), "missing.json"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := tt.client.ResumeSession(ctx, tt.path, tt.email)
+			if ok != tt.want || err != nil {
+				t.Errorf("ResumeSession = %v, %v, want %v", ok, err, tt.want)
+			}
+			if _, err := tt.client.LoadMonth(ctx, 2026, 10); (err == nil) != tt.want {
+				t.Errorf("LoadMonth after resuming: %v", err)
+			}
+		})
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		s.ExpireSessions()
+		c := s.NewClient()
+		ok, err := c.ResumeSession(ctx, path, testEmail)
+		if ok || err != nil {
+			t.Fatalf("ResumeSession = %v, %v, want false to log in again", ok, err)
+		}
+		// The password login the caller falls back to replaces the cookies
+		if err := c.Login(ctx, testEmail, testPassword); err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+		if _, err := c.LoadMonth(ctx, 2026, 10); err != nil {
+			t.Errorf("LoadMonth after logging in again: %v", err)
+		}
+		if err := c.Session(testEmail).Save(path); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		if ok, err := s.NewClient().ResumeSession(ctx, path, testEmail); !ok || err != nil {
+			t.Errorf("ResumeSession of the new session = %v, %v, want true", ok, err)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package factorial
+
+import (
+	"math"
+	"time"
+)
+
+// DayState says how much of a day's expected time is recorded
+type DayState string
+
+const (
+	DayComplete DayState = "complete"
+	DayPartial  DayState = "partial"
+	DayMissing  DayState = "missing"
+	// DayOff is a day without expected time: weekends, holidays and leaves
+	DayOff DayState = "off"
+)
+
+// DayStatus summarizes what's recorded for a day
+type DayStatus struct {
+	Date      time.Time
+	Laborable bool
+	Leave     string
+	Expected  int
+	Tracked   int
+	State     DayState
+	Shifts    []Shift
+}
+
+// MonthStatus summarizes what's recorded for a month. Balances are tracked
+// minus expected minutes, negative for a deficit.
+type MonthStatus struct {
+	Days     []DayStatus
+	Expected int
+	Tracked  int
+	Balance  int
+	// The same totals counting only the days up to now
+	ExpectedToDate int
+	TrackedToDate  int
+	BalanceToDate  int
+}
+
+// Status compares the expected and tracked minutes of every day of the month
+// within its range. Expected minutes are rounded like clock in rounds them.
+// Tracked minutes come from the period's distribution, or from the shifts
+// when Factorial doesn't return it.
+func (m *Month) Status(now time.Time) MonthStatus {
+	var status MonthStatus
+	tracked := m.Period.TrackedMinutesDistribution
+	todayKey := now.Format("2006-01-02")
+
+	for i, day := range m.Calendar {
+		if !m.InRange(day.Day) {
+			continue
+		}
+		d := DayStatus{
+			Date:      m.Date(day.Day),
+			Laborable: day.IsLaborable,
+			Expected:  int(math.Round(day.MinutesLeft)),
+		}
+		if day.IsLeave {
+			d.Leave = day.LeaveName
+		}
+		for _, s := range m.Shifts {
+			if s.Day == day.Day {
+				d.Shifts = append(d.Shifts, s)
+			}
+		}
+		if len(tracked) == len(m.Calendar) {
+			d.Tracked = tracked[i]
+		} else {
+			d.Tracked = shiftMinutes(d.Shifts)
+		}
+
+		switch {
+		case d.Expected == 0:
+			d.State = DayOff
+		case d.Tracked >= d.Expected:
+			d.State = DayComplete
+		case d.Tracked > 0:
+			d.State = DayPartial
+		default:
+			d.State = DayMissing
+		}
+
+		status.Days = append(status.Days, d)
+		status.Expected += d.Expected
+		status.Tracked += d.Tracked
+		if d.Date.Format("2006-01-02") <= todayKey {
+			status.ExpectedToDate += d.Expected
+			status.TrackedToDate += d.Tracked
+		}
+	}
+	status.Balance = status.Tracked - status.Expected
+	status.BalanceToDate = status.TrackedToDate - status.ExpectedToDate
+	return status
+}
+
+// RangeStatus adds up the status of several months
+func RangeStatus(months []*Month, now time.Time) MonthStatus {
+	var status MonthStatus
+	for _, m := range months {
+		s := m.Status(now)
+		status.Days = append(status.Days, s.Days...)
+		status.Expected += s.Expected
+		status.Tracked += s.Tracked
+		status.Balance += s.Balance
+		status.ExpectedToDate += s.ExpectedToDate
+		status.TrackedToDate += s.TrackedToDate
+		status.BalanceToDate += s.BalanceToDate
+	}
+	return status
+}
+
+// shiftMinutes adds up the minutes of closed shifts
+func shiftMinutes(shifts []Shift) int {
+	total := 0
+	for _, s := range shifts {
+		if s.Minutes > 0 {
+			total += int(s.Minutes)
+			continue
+		}
+		in, errIn := parseClock(s.ClockIn)
+		out, errOut := parseClock(s.ClockOut)
+		if errIn == nil && errOut == nil && out > in {
+			total += out - in
+		}
+	}
+	return total
+}
@@ -0,0 +1,61 @@
+package factorial_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alejoar/factorialsucks/factorial"
+)
+
+func TestStatus(t *testing.T) {
+	s := newServer(t)
+	s.AddShift("2026-10-01", "09:00", "17:15")
+	s.AddShift("2026-10-02", "08:00", "12:00")
+	// Half a minute is expected like clock in would record it
+	s.SetExpectedMinutes("2026-10-07", 247.5)
+	s.AddShift("2026-10-07", "09:00", "13:07")
+	c := login(t, s)
+
+	status := loadMonth(t, c).Status(time.Date(2026, 10, 7, 12, 0, 0, 0, time.UTC))
+	if len(status.Days) != 31 {
+		t.Fatalf("%d days, want 31", len(status.Days))
+	}
+	tests := []struct {
+		day      int
+		state    factorial.DayState
+		expected int
+		tracked  int
+		leave    string
+	}{
+		{1, factorial.DayComplete, 495, 495, ""},
+		{2, factorial.DayPartial, 420, 240, ""},
+		{3, factorial.DayOff, 0, 0, ""},
+		{5, factorial.DayMissing, 495, 0, ""},
+		{7, factorial.DayPartial, 248, 247, ""},
+		{12, factorial.DayOff, 0, 0, ""},
+		{20, factorial.DayOff, 0, 0, "Vacation"},
+		{30, factorial.DayMissing, 420, 0, ""},
+	}
+	for _, tt := range tests {
+		d := status.Days[tt.day-1]
+		if d.State != tt.state || d.Expected != tt.expected || d.Tracked != tt.tracked || d.Leave != tt.leave {
+			t.Errorf("day %d = %s %d/%d %q, want %s %d/%d %q", tt.day, d.State, d.Tracked, d.Expected, d.Leave, tt.state, tt.tracked, tt.expected, tt.leave)
+		}
+	}
+
+	// 14 regular days and 5 Fridays besides the 7th, up to the 7th 4 days
+	// and the 7th
+	want := factorial.MonthStatus{
+		Expected:       14*495 + 5*420 + 248,
+		Tracked:        495 + 240 + 247,
+		ExpectedToDate: 3*495 + 420 + 248,
+		TrackedToDate:  495 + 240 + 247,
+	}
+	want.Balance = want.Tracked - want.Expected
+	want.BalanceToDate = want.TrackedToDate - want.ExpectedToDate
+	status.Days = nil
+	if !reflect.DeepEqual(status, want) {
+		t.Errorf("totals = %+v, want %+v", status, want)
+	}
+}
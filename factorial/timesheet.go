@@ -0,0 +1,116 @@
+package factorial
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReadTimesheet reads planned shifts from CSV. Each row has a date
+// (YYYY-MM-DD), a clock in and a clock out (HH:MM) followed by optional break
+// columns, each with one or more HH:MM-HH:MM windows separated by ";". With a
+// header row the columns are found by name instead: date, clock_in, clock_out
+// and any column starting with break, other columns are ignored. This reads
+// the CSV written by WriteCSV too, whose open shifts, the rows with a clock
+// in and no clock out, are skipped.
+func ReadTimesheet(r io.Reader) ([]PlannedShift, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	// Without a header, every column after the clock out is a break
+	date, clockIn, clockOut := 0, 1, 2
+	var breaks []int
+	header := false
+	if _, err := time.Parse("2006-01-02", strings.TrimSpace(rows[0][0])); err != nil {
+		header = true
+		date, clockIn, clockOut = -1, -1, -1
+		for i, name := range rows[0] {
+			name = strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(name)))
+			switch {
+			case name == "date":
+				date = i
+			case name == "clock_in" || name == "in":
+				clockIn = i
+			case name == "clock_out" || name == "out":
+				clockOut = i
+			case strings.HasPrefix(name, "break"):
+				breaks = append(breaks, i)
+			}
+		}
+		if date < 0 || clockIn < 0 || clockOut < 0 {
+			return nil, errors.New("the header needs date, clock_in and clock_out columns")
+		}
+		rows = rows[1:]
+	}
+
+	var shifts []PlannedShift
+	for n, row := range rows {
+		number := n + 1
+		if header {
+			number++
+		}
+		cell := func(i int) string {
+			if i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+
+		s := PlannedShift{ClockIn: cell(clockIn), ClockOut: cell(clockOut)}
+		if s.Date, err = time.Parse("2006-01-02", cell(date)); err != nil {
+			return nil, fmt.Errorf("row %d: invalid date %q, expected YYYY-MM-DD", number, cell(date))
+		}
+		if s.ClockIn != "" && s.ClockOut == "" {
+			continue
+		}
+		columns := breaks
+		if !header {
+			columns = nil
+			for i := 3; i < len(row); i++ {
+				columns = append(columns, i)
+			}
+		}
+		for _, i := range columns {
+			for _, window := range strings.Split(cell(i), ";") {
+				if window = strings.TrimSpace(window); window == "" {
+					continue
+				}
+				parts := strings.Split(window, "-")
+				if len(parts) != 2 {
+					return nil, fmt.Errorf("row %d: invalid break %q, expected HH:MM-HH:MM", number, window)
+				}
+				s.Breaks = append(s.Breaks, Segment{Start: strings.TrimSpace(parts[0]), End: strings.TrimSpace(parts[1])})
+			}
+		}
+		// Break columns needn't be in time order
+		sort.SliceStable(s.Breaks, func(i, j int) bool {
+			a, _ := parseClock(s.Breaks[i].Start)
+			b, _ := parseClock(s.Breaks[j].Start)
+			return a < b
+		})
+		if err := checkTimes(s.ClockIn, s.ClockOut, s.Breaks); err != nil {
+			return nil, fmt.Errorf("row %d: %w", number, err)
+		}
+		s.ClockIn, s.ClockOut = normalizeClock(s.ClockIn), normalizeClock(s.ClockOut)
+		for i := range s.Breaks {
+			s.Breaks[i] = Segment{Start: normalizeClock(s.Breaks[i].Start), End: normalizeClock(s.Breaks[i].End)}
+		}
+		shifts = append(shifts, s)
+	}
+	return shifts, nil
+}
@@ -0,0 +1,129 @@
+package factorial
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// plannedTimes lists planned shifts as date, times and breaks
+func plannedTimes(shifts []PlannedShift) []string {
+	var times []string
+	for _, s := range shifts {
+		shift := s.Date.Format("2006-01-02") + " " + s.ClockIn + " - " + s.ClockOut
+		for _, b := range s.Breaks {
+			shift += " break " + b.Start + " - " + b.End
+		}
+		times = append(times, shift)
+	}
+	return times
+}
+
+func TestReadTimesheet(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want []string
+		err  string
+	}{
+		{
+			name: "without header",
+			csv: "2026-10-05,09:00,18:00,14:00-15:00\n" +
+				"2026-10-06, 8:00 ,16:00,11:00-11:15;13:00-13:30\n" +
+				"\n" +
+				"2026-10-07,10:00,14:00\n",
+			want: []string{
+				"2026-10-05 09:00 - 18:00 break 14:00 - 15:00",
+				"2026-10-06 08:00 - 16:00 break 11:00 - 11:15 break 13:00 - 13:30",
+				"2026-10-07 10:00 - 14:00",
+			},
+		},
+		{
+			name: "without header several break columns",
+			csv:  "2026-10-05,08:00,18:00,10:00-10:15,13:00-14:00;16:00-16:10,\n",
+			want: []string{"2026-10-05 08:00 - 18:00 break 10:00 - 10:15 break 13:00 - 14:00 break 16:00 - 16:10"},
+		},
+		{
+			name: "header in another order",
+			csv: "Notes,Clock Out,Break Lunch,Date,Clock-In,break_coffee\n" +
+				"ignored,18:00,14:00-15:00,2026-10-05,09:00,11:00-11:15\n" +
+				",17:00,,2026-10-06,09:00,\n",
+			want: []string{
+				"2026-10-05 09:00 - 18:00 break 11:00 - 11:15 break 14:00 - 15:00",
+				"2026-10-06 09:00 - 17:00",
+			},
+		},
+		{
+			name: "short names",
+			csv:  "date,in,out\n2026-10-05,09:00,13:00\n",
+			want: []string{"2026-10-05 09:00 - 13:00"},
+		},
+		{
+			name: "empty",
+			csv:  "",
+		},
+		{
+			name: "header without clock out",
+			csv:  "date,clock_in,end\n2026-10-05,09:00,13:00\n",
+			err:  "the header needs date, clock_in and clock_out columns",
+		},
+		{
+			name: "invalid date numbered after the header",
+			csv:  "date,clock_in,clock_out\n2026-10-05,09:00,13:00\n05/10/2026,09:00,13:00\n",
+			err:  `row 3: invalid date "05/10/2026", expected YYYY-MM-DD`,
+		},
+		{
+			name: "invalid break",
+			csv:  "2026-10-05,09:00,18:00,14:00\n",
+			err:  `row 1: invalid break "14:00", expected HH:MM-HH:MM`,
+		},
+		{
+			name: "break outside the shift",
+			csv:  "2026-10-05,09:00,13:00\n2026-10-06,09:00,13:00,12:00-14:00\n",
+			err:  "row 2: times must be increasing (13:00)",
+		},
+		{
+			name: "invalid time",
+			csv:  "2026-10-05,9am,13:00\n",
+			err:  `row 1: invalid time "9am", expected HH:MM`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shifts, err := ReadTimesheet(strings.NewReader(tt.csv))
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("ReadTimesheet error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadTimesheet: %v", err)
+			}
+			if got := plannedTimes(shifts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadTimesheet = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportImportCSV(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteCSV(&b, exportShifts); err != nil {
+		t.Fatal(err)
+	}
+	shifts, err := ReadTimesheet(&b)
+	if err != nil {
+		t.Fatalf("ReadTimesheet: %v", err)
+	}
+	// The open shift is left out
+	want := []string{
+		"2026-10-05 09:00 - 13:00",
+		"2026-10-05 14:00 - 18:30",
+		"2026-10-06 09:00 - 17:00",
+	}
+	if got := plannedTimes(shifts); !reflect.DeepEqual(got, want) {
+		t.Errorf("read back %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,34 @@
+package factorial
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimestampDST(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skip("no time zone data:", err)
+	}
+	c := &Client{TimeZone: madrid}
+
+	tests := []struct {
+		date, clock string
+		want        string
+	}{
+		// Summer time starts on 2026-03-29 and ends on 2026-10-25
+		{"2026-03-27", "09:00", "2026-03-27T09:00:00+01:00"},
+		{"2026-03-30", "09:00", "2026-03-30T09:00:00+02:00"},
+		{"2026-10-23", "17:30", "2026-10-23T17:30:00+02:00"},
+		{"2026-10-26", "17:30", "2026-10-26T17:30:00+01:00"},
+	}
+	for _, tt := range tests {
+		if got := c.timestamp(tt.date, tt.clock); got != tt.want {
+			t.Errorf("timestamp(%s, %s) = %s, want %s", tt.date, tt.clock, got, tt.want)
+		}
+		date, _ := time.ParseInLocation("2006-01-02", tt.date, madrid)
+		if got := stepTime(date, tt.clock).Format(time.RFC3339); got != tt.want {
+			t.Errorf("stepTime(%s, %s) = %s, want %s", tt.date, tt.clock, got, tt.want)
+		}
+	}
+}
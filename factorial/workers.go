@@ -0,0 +1,60 @@
+package factorial
+
+import (
+	"context"
+	"sync"
+)
+
+// runOrdered calls run for the indexes 0 to n-1 on up to workers goroutines,
+// and done for each index in order once it and the ones before have run.
+// done is called from the calling goroutine, and not for the indexes left
+// when ctx is done.
+func runOrdered(ctx context.Context, n, workers int, run func(int), done func(int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	type finished struct {
+		index int
+		ran   bool
+	}
+	jobs := make(chan int)
+	results := make(chan finished)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				ran := ctx.Err() == nil
+				if ran {
+					run(i)
+				}
+				results <- finished{i, ran}
+			}
+		}()
+	}
+	go func() {
+		for i := 0; i < n; i++ {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	ran := make([]bool, n)
+	seen := make([]bool, n)
+	next := 0
+	for r := range results {
+		seen[r.index], ran[r.index] = true, r.ran
+		for ; next < n && seen[next]; next++ {
+			if ran[next] {
+				done(next)
+			}
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"time"
@@ -8,11 +11,15 @@ import (
 	_ "github.com/joho/godotenv/autoload"
 
 	"github.com/alejoar/factorialsucks/factorial"
+	"github.com/briandowns/spinner"
 	"github.com/urfave/cli/v2"
 )
 
 var today time.Time = time.Now()
 
+// timeZone is the company's time zone, set from --timezone
+var timeZone = time.Local
+
 func main() {
 	log.SetFlags(0)
 	app := &cli.App{
@@ -20,99 +27,309 @@ func main() {
 		Usage:           "FactorialHR auto clock in for the whole month from the command line",
 		Version:         "2.1",
 		Compiled:        time.Now(),
-		UsageText:       "factorialsucks [options]",
+		UsageText:       "factorialsucks [global options] command [command options]",
 		HideHelpCommand: true,
 		HideVersion:     true,
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:    "email",
-				Aliases: []string{"e"},
-				Usage:   "you factorial email address",
-			},
-			&cli.IntFlag{
-				Name:        "year",
-				Aliases:     []string{"y"},
-				Usage:       "clock-in year `YYYY`",
-				DefaultText: "current year",
-				Value:       today.Year(),
-			},
-			&cli.IntFlag{
-				Name:        "month",
-				Aliases:     []string{"m"},
-				Usage:       "clock-in month `MM`",
-				DefaultText: "current month",
-				Value:       int(today.Month()),
-			},
-			&cli.StringFlag{
-				Name:    "clock-in",
-				Aliases: []string{"ci"},
-				Usage:   "clock-in time `HH:MM`",
-				Value:   "09:00",
-			},
-			&cli.StringFlag{
-				Name:    "clock-out",
-				Aliases: []string{"co"},
-				Usage:   "clock-in time `HH:MM`",
-				Value:   "18:00",
-			},
-			&cli.BoolFlag{
-				Name:    "today",
-				Aliases: []string{"t"},
-				Usage:   "clock in for today only",
-				Value:   false,
-			},
-			&cli.BoolFlag{
-				Name:    "until-today",
-				Aliases: []string{"ut"},
-				Usage:   "clock in only until today",
-				Value:   false,
-			},
-			&cli.BoolFlag{
-				Name:    "dry-run",
-				Aliases: []string{"dr"},
-				Usage:   "do a dry run without actually clocking in",
-			},
+		// The clock flags are kept at the top level so that running without a
+		// command still clocks in (or resets with --reset-month)
+		Flags: append(globalFlags(), append(clockFlags(true),
 			&cli.BoolFlag{
 				Name:    "reset-month",
 				Aliases: []string{"rm"},
 				Usage:   "delete all shifts for the given month",
 				Value:   false,
+				Hidden:  true,
+			},
+			&cli.BoolFlag{
+				Name:   "yes",
+				Usage:  "reset without asking for confirmation",
+				Hidden: true,
 			},
+		)...),
+		Commands: []*cli.Command{
+			clockCommand(),
+			resetCommand(),
+			restoreCommand(),
+			exportCommand(),
+			importCommand(),
+			daemonCommand(),
+			statusCommand(),
+			reportCommand(),
+			leavesCommand(),
+			configCommand(),
+		},
+		Before: func(c *cli.Context) error {
+			p, err := newPrinter(c.String("output"))
+			if err != nil {
+				return err
+			}
+			out = p
+			if name := c.String("timezone"); name != "" {
+				if timeZone, err = time.LoadLocation(name); err != nil {
+					return fmt.Errorf("invalid time zone %q: %w", name, err)
+				}
+			}
+			today = today.In(timeZone)
+			return nil
 		},
 		Action: factorialSucks,
 	}
 
 	err := app.Run(os.Args)
 	if err != nil {
-		log.Fatal(err)
+		log.Println(err)
+		if out.structured() && !out.done {
+			out.summary(errorSummary{Type: "summary", Error: err.Error(), ExitCode: exitCode(err)})
+		}
+		os.Exit(exitCode(err))
 	}
 }
 
+// factorialSucks runs the command line of previous versions, without a
+// command
 func factorialSucks(c *cli.Context) error {
-	var year, month int
-	//email, password := readCredentials(c)
-	email := os.Getenv("EMAIL")
+	if c.Bool("reset-month") {
+		return reset(c)
+	}
+	return clock(c)
+}
+
+func globalFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "email",
+			Aliases: []string{"e"},
+			Usage:   "you factorial email address",
+			EnvVars: []string{"EMAIL"},
+		},
+		&cli.StringFlag{
+			Name:        "session",
+			Usage:       "save the login session to `FILE` and reuse it on later runs",
+			DefaultText: "factorialsucks/session.json in your config directory",
+		},
+		&cli.BoolFlag{
+			Name:  "no-session",
+			Usage: "always log in with the password, without saving the session",
+		},
+		&cli.IntFlag{
+			Name:  "max-retries",
+			Usage: "retry failed requests up to `N` times",
+			Value: factorial.DefaultMaxRetries,
+		},
+		&cli.Float64Flag{
+			Name:  "rate",
+			Usage: "send at most `N` requests per second (0 for no limit)",
+			Value: 5,
+		},
+		&cli.StringFlag{
+			Name:        "timezone",
+			Aliases:     []string{"tz"},
+			Usage:       "company time zone `NAME`, e.g. Europe/Madrid, for the days and shift times",
+			DefaultText: "local time zone",
+			EnvVars:     []string{"TIMEZONE"},
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "print results as `FORMAT`: text, json or ndjson",
+			Value:   outputText,
+		},
+		&cli.StringFlag{
+			Name:    "base-url",
+			Usage:   "Factorial API `URL`",
+			Value:   factorial.BaseUrl,
+			EnvVars: []string{"BASE_URL"},
+			Hidden:  true,
+		},
+	}
+}
+
+// rangeFlags select the days a command works on, a month or a range of dates
+func rangeFlags(hidden bool) []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:        "year",
+			Aliases:     []string{"y"},
+			Usage:       "year `YYYY`",
+			DefaultText: "current year",
+			Value:       today.Year(),
+			Hidden:      hidden,
+		},
+		&cli.IntFlag{
+			Name:        "month",
+			Aliases:     []string{"m"},
+			Usage:       "month `MM`",
+			DefaultText: "current month",
+			Value:       int(today.Month()),
+			Hidden:      hidden,
+		},
+		&cli.StringFlag{
+			Name:        "from",
+			Usage:       "first day `YYYY-MM-DD`, instead of --year and --month",
+			DefaultText: "first day of the month of --to",
+			Hidden:      hidden,
+		},
+		&cli.StringFlag{
+			Name:        "to",
+			Usage:       "last day `YYYY-MM-DD`, instead of --year and --month",
+			DefaultText: "last day of the month of --from",
+			Hidden:      hidden,
+		},
+	}
+}
+
+// Exit codes
+const (
+	exitError          = 1 // usage or unexpected errors
+	exitAuth           = 2 // login failed or session rejected
+	exitPeriodNotFound = 3 // no attendance period for the month
+	exitDaysFailed     = 4 // the run finished but some days failed
+	exitTransport      = 5 // Factorial couldn't be reached
+)
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var authErr *factorial.AuthError
+	var periodErr *factorial.PeriodNotFoundError
+	var runErr *factorial.RunError
+	var transportErr *factorial.TransportError
+	switch {
+	case errors.As(err, &authErr):
+		return exitAuth
+	case errors.As(err, &periodErr):
+		return exitPeriodNotFound
+	case errors.As(err, &runErr):
+		return exitDaysFailed
+	case errors.As(err, &transportErr):
+		return exitTransport
+	}
+	return exitError
+}
+
+// newSpinner creates the progress spinner, which doesn't draw anything when
+// the output is JSON
+func newSpinner() *spinner.Spinner {
+	spin := spinner.New(spinner.CharSets[14], 60*time.Millisecond)
+	if out.structured() {
+		spin.Writer = ioutil.Discard
+	}
+	return spin
+}
+
+// newClient creates a client configured by the global flags and logs in
+func newClient(c *cli.Context, spin *spinner.Spinner) (*factorial.Client, error) {
+	client := factorial.NewClient()
+	client.BaseUrl = c.String("base-url")
+	client.Transport = factorial.NewRetryTransport(c.Int("max-retries"), c.Float64("rate"))
+	client.TimeZone = timeZone
+	spin.Suffix = " Logging in..."
+	if err := login(c, client, c.String("email"), spin); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// selectedRange returns the days selected by --from and --to, or the month
+// selected by --year and --month. --today always selects the current month.
+func selectedRange(c *cli.Context) (factorial.DateRange, error) {
+	if c.Bool("today") {
+		return factorial.MonthRange(today.Year(), int(today.Month())), nil
+	}
+
+	from, to := c.String("from"), c.String("to")
+	if from == "" && to == "" {
+		// The defaults were taken before the time zone was known
+		year, month := today.Year(), int(today.Month())
+		if c.IsSet("year") {
+			year = c.Int("year")
+		}
+		if c.IsSet("month") {
+			month = c.Int("month")
+		}
+		if month < 1 || month > 12 {
+			return factorial.DateRange{}, fmt.Errorf("invalid month %d", month)
+		}
+		return factorial.MonthRange(year, month), nil
+	}
+	if from == "" {
+		if date, err := time.Parse("2006-01-02", to); err == nil {
+			from = date.AddDate(0, 0, 1-date.Day()).Format("2006-01-02")
+		}
+	}
+	if to == "" {
+		if date, err := time.Parse("2006-01-02", from); err == nil {
+			to = factorial.MonthRange(date.Year(), int(date.Month())).To.Format("2006-01-02")
+		}
+	}
+	return factorial.ParseDateRange(from, to)
+}
+
+// loadMonths logs in and fetches the months covering the selected days
+func loadMonths(c *cli.Context, spin *spinner.Spinner) (*factorial.Client, []*factorial.Month, factorial.DateRange, error) {
+	r, err := selectedRange(c)
+	if err != nil {
+		return nil, nil, r, err
+	}
+	client, months, err := loadRange(c, spin, r)
+	return client, months, r, err
+}
+
+// loadRange logs in and fetches the months covering a range
+func loadRange(c *cli.Context, spin *spinner.Spinner, r factorial.DateRange) (*factorial.Client, []*factorial.Month, error) {
+	client, err := newClient(c, spin)
+	if err != nil {
+		return nil, nil, err
+	}
+	spin.Suffix = " Getting month data..."
+	months, err := client.LoadRange(c.Context, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, months, nil
+}
+
+// sessionPath returns where the session is saved, or an empty string when
+// sessions are disabled
+func sessionPath(c *cli.Context) (string, error) {
+	if c.Bool("no-session") {
+		return "", nil
+	}
+	if path := c.String("session"); path != "" {
+		return path, nil
+	}
+	return factorial.DefaultSessionPath()
+}
+
+// login reuses the saved session if it's still valid, and logs in with the
+// password otherwise, saving the new session
+func login(c *cli.Context, client *factorial.Client, email string, spin *spinner.Spinner) error {
+	ctx := c.Context
+	path, err := sessionPath(c)
+	if err != nil {
+		log.Println("Not saving the session:", err)
+	}
+
+	if path != "" {
+		if ok, err := client.ResumeSession(ctx, path, email); ok || err != nil {
+			return err
+		}
+	}
+
 	password := os.Getenv("PASSWORD")
-	todayOnly := c.Bool("today")
-	if todayOnly {
-		year = today.Year()
-		month = int(today.Month())
-	} else {
-		year = c.Int("year")
-		month = int(today.Month())
-	}
-	clockIn := c.String("clock-in")
-	clockOut := c.String("clock-out")
-	dryRun := c.Bool("dry-run")
-	untilToday := c.Bool("until-today")
-	resetMonth := c.Bool("reset-month")
-	//reset_month = true
-
-	client := factorial.NewFactorialClient(email, password, year, month, clockIn, clockOut, todayOnly, untilToday)
-	if resetMonth {
-		client.ResetMonth()
-	} else {
-		client.ClockIn(dryRun)
+	if password == "" {
+		spin.Stop()
+		password = readPassword()
+		spin.Start()
+	}
+	if err := client.Login(ctx, email, password); err != nil {
+		return err
+	}
+	if path != "" {
+		if err := client.Session(email).Save(path); err != nil {
+			log.Println("Could not save the session:", err)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alejoar/factorialsucks/factorial"
+	"github.com/urfave/cli/v2"
+)
+
+func importCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "import",
+		Usage:     "record the shifts of a CSV timesheet or an iCalendar file",
+		UsageText: "factorialsucks import [options] FILE",
+		Description: "Records the shifts of a CSV timesheet with a date, clock in, clock out and optional break\n" +
+			"columns, one shift per row. Leaves and non-laborable days are skipped, and shifts overlapping\n" +
+			"the ones already recorded too unless --on-overlap says otherwise.\n\n" +
+			"Files ending in .ics are read as iCalendar: the events of each day matching --category\n" +
+			"or --summary (Work by default) are its worked time, and the gaps between them its breaks.\n" +
+			"Daily and weekly recurring events are expanded.\n\n" +
+			"Only the days of --year and --month, or --from and --to, are imported. iCalendar files\n" +
+			"default to the current month, timesheets to all their rows.",
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"dr"},
+				Usage:   "do a dry run without actually recording the shifts",
+			},
+			&cli.StringSliceFlag{
+				Name:  "category",
+				Usage: "import the iCalendar events in `CATEGORY`",
+			},
+			&cli.StringSliceFlag{
+				Name:  "summary",
+				Usage: "import the iCalendar events titled `SUMMARY`",
+			},
+			&cli.StringFlag{
+				Name:        "location",
+				Aliases:     []string{"l"},
+				Usage:       "record the shifts as worked from `LOCATION`: office, work_from_home or business_trip",
+				DefaultText: "work_from_home",
+			},
+			&cli.StringFlag{
+				Name:  "on-failure",
+				Usage: "what to do when a shift with breaks fails half way: rollback its segments or resume from the last step recorded (`MODE`)",
+				Value: string(factorial.RecoveryRollback),
+			},
+			overlapFlag(false),
+			replaceBackupFlag(false),
+		}, rangeFlags(false)...),
+		Action: importTimesheet,
+	}
+}
+
+func importTimesheet(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("import needs the timesheet FILE")
+	}
+	opts := factorial.ImportOptions{DryRun: c.Bool("dry-run"), Backup: c.String("backup")}
+	recovery, err := factorial.ParseRecovery(c.String("on-failure"))
+	if err != nil {
+		return err
+	}
+	opts.Recovery = recovery
+	if opts.Overlap, err = factorial.ParseOverlapPolicy(c.String("on-overlap")); err != nil {
+		return err
+	}
+	if opts.Overlap == factorial.OverlapFillGaps {
+		return errors.New("--on-overlap fill-gaps only applies to clock, imported shifts keep their times: use skip or replace")
+	}
+	if opts.Location, err = selectedLocation(c); err != nil {
+		return err
+	}
+
+	r, err := selectedRange(c)
+	if err != nil {
+		return err
+	}
+	ranged := c.IsSet("year") || c.IsSet("month") || c.IsSet("from") || c.IsSet("to")
+
+	path := c.Args().First()
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	var shifts []factorial.PlannedShift
+	if strings.EqualFold(filepath.Ext(path), ".ics") {
+		shifts, err = factorial.ReadICS(f, factorial.ICSOptions{
+			Categories: splitList(c.StringSlice("category")),
+			Summaries:  splitList(c.StringSlice("summary")),
+			Location:   timeZone,
+			Range:      r,
+		})
+	} else if shifts, err = factorial.ReadTimesheet(f); err == nil && ranged {
+		shifts = plannedIn(shifts, r)
+	}
+	f.Close()
+	if err != nil {
+		return err
+	}
+	return importShifts(c, shifts, opts)
+}
+
+// plannedIn returns the shifts on the days of a range
+func plannedIn(shifts []factorial.PlannedShift, r factorial.DateRange) []factorial.PlannedShift {
+	var kept []factorial.PlannedShift
+	for _, s := range shifts {
+		if r.Contains(s.Date) {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// importShifts records planned shifts, loading the months they cover
+func importShifts(c *cli.Context, shifts []factorial.PlannedShift, opts factorial.ImportOptions) error {
+	summary := clockSummary{Type: "summary", File: c.Args().First(), DryRun: opts.DryRun}
+	if len(shifts) == 0 {
+		return finishRun(summary, nil)
+	}
+	r := factorial.PlannedRange(shifts)
+	summary.From, summary.To = r.From.Format("2006-01-02"), r.To.Format("2006-01-02")
+
+	spin := newSpinner()
+	spin.Start()
+	defer spin.Stop()
+	client, months, err := loadRange(c, spin, r)
+	if err != nil {
+		return err
+	}
+
+	spin.Suffix = " Importing shifts..."
+	err = client.Import(c.Context, months, shifts, opts, reportDay(spin, &summary))
+	spin.Stop()
+	return finishRun(summary, err)
+}
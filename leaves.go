@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alejoar/factorialsucks/factorial"
+	"github.com/urfave/cli/v2"
+)
+
+func leavesCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "leaves",
+		Usage:       "list the leaves and holidays of the month or range",
+		UsageText:   "factorialsucks leaves [options]",
+		Description: "Lists the days of the month, or of the --from/--to range, the tool skips because of a leave or a holiday.",
+		Flags:       rangeFlags(false),
+		Action:      leaves,
+	}
+}
+
+func leaves(c *cli.Context) error {
+	spin := newSpinner()
+	spin.Start()
+	defer spin.Stop()
+	_, months, r, err := loadMonths(c, spin)
+	if err != nil {
+		return err
+	}
+	spin.Stop()
+
+	summary := leavesSummary{Type: "summary", From: r.From.Format("2006-01-02"), To: r.To.Format("2006-01-02")}
+	for _, m := range months {
+		if err := printLeaves(m, &summary); err != nil {
+			return err
+		}
+	}
+	if out.structured() {
+		return out.summary(summary)
+	}
+	if summary.Leaves+summary.Holidays == 0 {
+		fmt.Println("No leaves or holidays found")
+	}
+	return nil
+}
+
+// printLeaves prints the leaves and holidays of a month within its range,
+// counting them in summary
+func printLeaves(m *factorial.Month, summary *leavesSummary) error {
+	for _, day := range m.Calendar {
+		if !m.InRange(day.Day) {
+			continue
+		}
+		date := m.Date(day.Day)
+		record := leaveRecord{Type: "day", Date: date.Format("2006-01-02")}
+		message := fmt.Sprintf("%s... ", date.Format("02 Jan"))
+		switch {
+		case day.PartialLeave():
+			record.Kind, record.Name = "partial_leave", day.Leaves[0].Name
+			record.Time = leaveTime(day)
+			record.MinutesLeft = int(day.MinutesLeft)
+			message = fmt.Sprintf("%s 🌴 %s (%s), %s left to work", message, record.Name, record.Time, formatMinutes(record.MinutesLeft))
+			summary.Leaves++
+		case day.IsLeave:
+			record.Kind, record.Name = "leave", day.LeaveName
+			message = fmt.Sprintf("%s 🌴 %s", message, day.LeaveName)
+			summary.Leaves++
+		case !day.IsLaborable && date.Weekday() != time.Saturday && date.Weekday() != time.Sunday:
+			record.Kind = "holiday"
+			message = fmt.Sprintf("%s 🎉 Holiday", message)
+			summary.Holidays++
+		default:
+			continue
+		}
+		if out.structured() {
+			if err := out.record(record); err != nil {
+				return err
+			}
+		} else {
+			fmt.Println(message)
+		}
+	}
+	return nil
+}
+
+// leaveTime describes the part of the day partial leaves take
+func leaveTime(day factorial.CalendarDay) string {
+	var parts []string
+	for _, l := range day.Leaves {
+		switch {
+		case l.StartTime != "" && l.EndTime != "":
+			parts = append(parts, l.StartTime+" - "+l.EndTime)
+		case l.StartTime != "":
+			parts = append(parts, fmt.Sprintf("%s from %s", formatMinutes(int(l.Minutes)), l.StartTime))
+		case l.HalfDay == factorial.HalfDayMorning:
+			parts = append(parts, "morning")
+		case l.HalfDay == factorial.HalfDayAfternoon:
+			parts = append(parts, "afternoon")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// leaveRecord is the JSON output of a leave or a holiday. Kind is leave,
+// partial_leave or holiday, partial leaves have the Time they take and the
+// minutes left to work.
+type leaveRecord struct {
+	Type        string `json:"type"`
+	Date        string `json:"date"`
+	Kind        string `json:"kind"`
+	Name        string `json:"name,omitempty"`
+	Time        string `json:"time,omitempty"`
+	MinutesLeft int    `json:"minutes_left,omitempty"`
+}
+
+// leavesSummary ends the JSON output of leaves
+type leavesSummary struct {
+	Type     string `json:"type"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Leaves   int    `json:"leaves"`
+	Holidays int    `json:"holidays"`
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Output formats
+const (
+	outputText   = "text"
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+)
+
+// printer writes the results of a command. In the JSON formats every day is a
+// record and the command ends with a summary object: ndjson prints each one on
+// its own line, json prints a single object with both once the command is done.
+type printer struct {
+	format  string
+	w       io.Writer
+	records []interface{}
+	done    bool
+}
+
+// out is the printer of the running command, set from --output
+var out = &printer{format: outputText, w: os.Stdout}
+
+func newPrinter(format string) (*printer, error) {
+	switch format {
+	case outputText, outputJSON, outputNDJSON:
+		return &printer{format: format, w: os.Stdout}, nil
+	}
+	return nil, fmt.Errorf("invalid output format %q, use %s, %s or %s", format, outputText, outputJSON, outputNDJSON)
+}
+
+// structured reports whether results are printed as JSON
+func (p *printer) structured() bool {
+	return p.format != outputText
+}
+
+// prompts is where to ask for input, stdout is kept for results in the JSON
+// formats
+func (p *printer) prompts() io.Writer {
+	if p.structured() {
+		return os.Stderr
+	}
+	return p.w
+}
+
+// record prints a per-day record, or keeps it for the summary in json
+func (p *printer) record(v interface{}) error {
+	if p.format == outputJSON {
+		p.records = append(p.records, v)
+		return nil
+	}
+	return json.NewEncoder(p.w).Encode(v)
+}
+
+// summary prints the final object of the command
+func (p *printer) summary(v interface{}) error {
+	p.done = true
+	if p.format == outputJSON {
+		records := p.records
+		if records == nil {
+			records = []interface{}{}
+		}
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Records []interface{} `json:"records"`
+			Summary interface{}   `json:"summary"`
+		}{records, v})
+	}
+	return json.NewEncoder(p.w).Encode(v)
+}
+
+// errorSummary ends the output of a command that failed before printing its
+// own summary
+type errorSummary struct {
+	Type     string `json:"type"`
+	Error    string `json:"error"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// errorString returns the message of err, or an empty string if it's nil
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alejoar/factorialsucks/factorial"
+	"github.com/urfave/cli/v2"
+)
+
+func reportCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "report",
+		Usage:       "summarize the hours worked in the month or range",
+		UsageText:   "factorialsucks report [options]",
+		Description: "Shows the tracked and expected hours of each week of the month, or of the --from/--to range, and the balance.",
+		Flags:       rangeFlags(false),
+		Action:      report,
+	}
+}
+
+func report(c *cli.Context) error {
+	spin := newSpinner()
+	spin.Start()
+	defer spin.Stop()
+	_, months, r, err := loadMonths(c, spin)
+	if err != nil {
+		return err
+	}
+	spin.Stop()
+
+	days := factorial.RangeStatus(months, today).Days
+	var weekTracked, weekExpected, totalTracked, totalExpected int
+	for i, d := range days {
+		weekTracked += d.Tracked
+		weekExpected += d.Expected
+		if d.Date.Weekday() == time.Sunday || i == len(days)-1 {
+			_, week := d.Date.ISOWeek()
+			if out.structured() {
+				if err := out.record(weekRecord{Type: "week", Week: week, Tracked: weekTracked, Expected: weekExpected}); err != nil {
+					return err
+				}
+			} else {
+				fmt.Printf("Week %02d... %s / %s\n", week, formatMinutes(weekTracked), formatMinutes(weekExpected))
+			}
+			totalTracked += weekTracked
+			totalExpected += weekExpected
+			weekTracked, weekExpected = 0, 0
+		}
+	}
+	if out.structured() {
+		return out.summary(reportSummary{
+			Type:     "summary",
+			From:     r.From.Format("2006-01-02"),
+			To:       r.To.Format("2006-01-02"),
+			Tracked:  totalTracked,
+			Expected: totalExpected,
+			Balance:  totalTracked - totalExpected,
+		})
+	}
+	fmt.Printf("Tracked:  %s\n", formatMinutes(totalTracked))
+	fmt.Printf("Expected: %s\n", formatMinutes(totalExpected))
+	fmt.Printf("Balance:  %s\n", formatBalance(totalTracked-totalExpected))
+	return nil
+}
+
+// weekRecord is the JSON output of a week of the report
+type weekRecord struct {
+	Type     string `json:"type"`
+	Week     int    `json:"week"`
+	Tracked  int    `json:"tracked_minutes"`
+	Expected int    `json:"expected_minutes"`
+}
+
+// reportSummary ends the JSON output of the report
+type reportSummary struct {
+	Type     string `json:"type"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Tracked  int    `json:"tracked_minutes"`
+	Expected int    `json:"expected_minutes"`
+	Balance  int    `json:"balance_minutes"`
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+
+	"github.com/alejoar/factorialsucks/factorial"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+func resetCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "reset",
+		Usage:     "delete the shifts of the month or range",
+		UsageText: "factorialsucks reset [options]",
+		Description: "Deletes the shifts recorded in the month, or in the --from/--to range, including the ones\n" +
+			"added by hand. --weekday, --source and --window narrow down the shifts deleted. The\n" +
+			"matching shifts are listed and confirmed first, and written to a backup file before\n" +
+			"deleting them.",
+		Flags: append(rangeFlags(false),
+			&cli.StringSliceFlag{
+				Name:  "weekday",
+				Usage: "only delete shifts on these weekdays (`DAY`, e.g. mon,fri)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "source",
+				Usage: "only delete shifts recorded from `SOURCE` (e.g. desktop, manual)",
+			},
+			&cli.StringFlag{
+				Name:  "window",
+				Usage: "only delete shifts that start and end within `HH:MM-HH:MM`, open shifts end at 24:00",
+			},
+			&cli.StringFlag{
+				Name:        "backup",
+				Usage:       "write the deleted shifts to `FILE`",
+				DefaultText: "factorialsucks/backups/reset-<time>.json in your config directory",
+			},
+			&cli.BoolFlag{
+				Name:  "yes",
+				Usage: "delete without asking for confirmation",
+			},
+		),
+		Action: reset,
+	}
+}
+
+func reset(c *cli.Context) error {
+	filter, err := factorial.ParseShiftFilter(c.StringSlice("weekday"), c.StringSlice("source"), c.String("window"))
+	if err != nil {
+		return err
+	}
+
+	spin := newSpinner()
+	spin.Start()
+	defer spin.Stop()
+	client, months, r, err := loadMonths(c, spin)
+	if err != nil {
+		return err
+	}
+	spin.Stop()
+
+	summary := resetSummary{Type: "summary", From: r.From.Format("2006-01-02"), To: r.To.Format("2006-01-02")}
+	backup := client.NewBackup()
+	for _, m := range months {
+		backup.Add(m, m.FindShifts(filter))
+	}
+	if len(backup.Shifts) == 0 {
+		if out.structured() {
+			return out.summary(summary)
+		}
+		fmt.Println("No shifts to delete")
+		return nil
+	}
+
+	for _, s := range backup.Shifts {
+		date, _ := time.Parse("2006-01-02", s.Date)
+		fmt.Fprintf(out.prompts(), "%s...  %s - %s (%s)\n", date.Format("02 Jan"), s.ClockIn, s.ClockOut, s.Source)
+	}
+	if !c.Bool("yes") && !terminal.IsTerminal(int(syscall.Stdin)) {
+		return fmt.Errorf("not deleting %d shift(s) without confirmation, pass --yes when not running in a terminal", len(backup.Shifts))
+	}
+	if !c.Bool("yes") && !confirm(fmt.Sprintf("Delete %d shift(s)?", len(backup.Shifts))) {
+		summary.Cancelled = true
+		if out.structured() {
+			return out.summary(summary)
+		}
+		fmt.Println("Nothing deleted")
+		return nil
+	}
+
+	path := c.String("backup")
+	if path == "" {
+		if path, err = factorial.DefaultBackupPath("reset", backup.SavedAt); err != nil {
+			return fmt.Errorf("could not write the backup: %w", err)
+		}
+	}
+	if err := backup.Save(path); err != nil {
+		return fmt.Errorf("could not write the backup: %w", err)
+	}
+	summary.Backup = path
+	fmt.Fprintf(out.prompts(), "Backup of %d shift(s) written to %s\n", len(backup.Shifts), path)
+
+	spin.Suffix = " Deleting shifts..."
+	spin.Start()
+	err = client.ResetMonths(c.Context, months, filter, func(r factorial.ResetResult) {
+		spin.Stop()
+		if r.Err != nil {
+			summary.Failed++
+		} else {
+			summary.Deleted++
+		}
+		if out.structured() {
+			out.record(newResetRecord(r))
+		} else {
+			printReset(r)
+		}
+		spin.Start()
+	})
+	spin.Stop()
+	if out.structured() {
+		summary.Error = errorString(err)
+		summary.ExitCode = exitCode(err)
+		if err := out.summary(summary); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !out.structured() {
+		fmt.Println("done!")
+	}
+	return nil
+}
+
+// resetRecord is the JSON output of a deleted shift. Action is deleted or
+// failed.
+type resetRecord struct {
+	Type     string              `json:"type"`
+	Date     string              `json:"date"`
+	Action   string              `json:"action"`
+	ShiftId  int64               `json:"shift_id"`
+	Segments []factorial.Segment `json:"segments"`
+	Error    string              `json:"error,omitempty"`
+}
+
+func newResetRecord(r factorial.ResetResult) resetRecord {
+	record := resetRecord{
+		Type:     "day",
+		Date:     r.Date.Format("2006-01-02"),
+		Action:   "deleted",
+		ShiftId:  r.Shift.Id,
+		Segments: []factorial.Segment{{Start: r.Shift.ClockIn, End: r.Shift.ClockOut}},
+		Error:    errorString(r.Err),
+	}
+	if r.Err != nil {
+		record.Action = "failed"
+	}
+	return record
+}
+
+// resetSummary ends the JSON output of a reset. Backup is the file the
+// deleted shifts were written to.
+type resetSummary struct {
+	Type      string `json:"type"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Cancelled bool   `json:"cancelled,omitempty"`
+	Backup    string `json:"backup,omitempty"`
+	Deleted   int    `json:"deleted"`
+	Failed    int    `json:"failed"`
+	Error     string `json:"error,omitempty"`
+	ExitCode  int    `json:"exit_code"`
+}
+
+func printReset(r factorial.ResetResult) {
+	message := fmt.Sprintf("%s... ", r.Date.Format("02 Jan"))
+	if r.Err != nil {
+		fmt.Printf("%s ❌ Error when attempting to delete shift: %s - %s: %v\n", message, r.Shift.ClockIn, r.Shift.ClockOut, r.Err)
+	} else {
+		fmt.Printf("%s ✅ Shift deleted: %s - %s\n", message, r.Shift.ClockIn, r.Shift.ClockOut)
+	}
+}
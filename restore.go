@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/alejoar/factorialsucks/factorial"
+	"github.com/urfave/cli/v2"
+)
+
+func restoreCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "restore",
+		Usage:     "re-create the shifts of a backup",
+		UsageText: "factorialsucks restore [options] FILE",
+		Description: "Re-creates the shifts of a JSON backup written by reset, or of a JSON array of shifts\n" +
+			"with their dates. Shifts overlapping the ones already recorded are skipped, so nothing\n" +
+			"is duplicated. Backups taken from another employee are refused unless --force is passed.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "restore a backup taken from another employee into your account",
+			},
+		},
+		Action: restore,
+	}
+}
+
+func restore(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("restore needs the backup FILE")
+	}
+	backup, err := factorial.LoadBackup(c.Args().First())
+	if err != nil {
+		return err
+	}
+	r, err := backup.Range()
+	if err != nil {
+		return err
+	}
+	summary := restoreSummary{Type: "summary", File: c.Args().First()}
+	if len(backup.Shifts) == 0 {
+		if out.structured() {
+			return out.summary(summary)
+		}
+		fmt.Println("No shifts to restore")
+		return nil
+	}
+	summary.From, summary.To = r.From.Format("2006-01-02"), r.To.Format("2006-01-02")
+
+	spin := newSpinner()
+	spin.Start()
+	defer spin.Stop()
+	client, months, err := loadRange(c, spin, r)
+	if err != nil {
+		return err
+	}
+	opts := factorial.RestoreOptions{Force: c.Bool("force")}
+	if opts.Force && backup.EmployeeId != 0 && backup.EmployeeId != months[0].EmployeeId {
+		spin.Stop()
+		log.Printf("The backup belongs to employee %d, restoring it for employee %d", backup.EmployeeId, months[0].EmployeeId)
+		spin.Start()
+	}
+
+	spin.Suffix = " Restoring shifts..."
+	err = client.Restore(c.Context, months, backup, opts, func(r factorial.RestoreResult) {
+		spin.Stop()
+		record := newRestoreRecord(r)
+		switch record.Action {
+		case "restored":
+			summary.Restored++
+		case "skipped":
+			summary.Skipped++
+		case "failed":
+			summary.Failed++
+		}
+		if out.structured() {
+			out.record(record)
+		} else {
+			printRestore(r)
+		}
+		spin.Start()
+	})
+	spin.Stop()
+	var mismatch *factorial.EmployeeMismatchError
+	if errors.As(err, &mismatch) {
+		err = fmt.Errorf("%w, pass --force to restore it anyway", err)
+	}
+	if out.structured() {
+		summary.Error = errorString(err)
+		summary.ExitCode = exitCode(err)
+		if err := out.summary(summary); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !out.structured() {
+		fmt.Println("done!")
+	}
+	return nil
+}
+
+func printRestore(r factorial.RestoreResult) {
+	message := fmt.Sprintf("%s... ", r.Date.Format("02 Jan"))
+	switch {
+	case r.Skipped:
+		fmt.Printf("%s ❌ %s - %s: %s\n", message, r.Shift.ClockIn, r.Shift.ClockOut, r.Reason)
+	case r.Err != nil:
+		fmt.Printf("%s ❌ Error when attempting to restore shift: %s - %s: %v\n", message, r.Shift.ClockIn, r.Shift.ClockOut, r.Err)
+	default:
+		fmt.Printf("%s ✅ Shift restored: %s - %s\n", message, r.Shift.ClockIn, r.Shift.ClockOut)
+	}
+}
+
+// restoreRecord is the JSON output of a restored shift. Action is restored,
+// skipped or failed.
+type restoreRecord struct {
+	Type     string              `json:"type"`
+	Date     string              `json:"date"`
+	Action   string              `json:"action"`
+	Reason   string              `json:"reason,omitempty"`
+	Segments []factorial.Segment `json:"segments"`
+	Error    string              `json:"error,omitempty"`
+}
+
+func newRestoreRecord(r factorial.RestoreResult) restoreRecord {
+	record := restoreRecord{
+		Type:     "day",
+		Date:     r.Shift.Date,
+		Action:   "restored",
+		Reason:   r.Reason,
+		Segments: []factorial.Segment{{Start: r.Shift.ClockIn, End: r.Shift.ClockOut}},
+		Error:    errorString(r.Err),
+	}
+	switch {
+	case r.Skipped:
+		record.Action = "skipped"
+	case r.Err != nil:
+		record.Action = "failed"
+	}
+	return record
+}
+
+// restoreSummary ends the JSON output of a restore
+type restoreSummary struct {
+	Type     string `json:"type"`
+	File     string `json:"file"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Restored int    `json:"restored"`
+	Skipped  int    `json:"skipped"`
+	Failed   int    `json:"failed"`
+	Error    string `json:"error,omitempty"`
+	ExitCode int    `json:"exit_code"`
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alejoar/factorialsucks/factorial"
+	"github.com/urfave/cli/v2"
+)
+
+func statusCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "status",
+		Usage:     "show the shifts, gaps and balance of the month or range",
+		UsageText: "factorialsucks status [options]",
+		Description: "Shows the expected and tracked time of each day of the month, or of the --from/--to\n" +
+			"range, whether it's complete, partial or missing, and the resulting overtime or deficit.\n" +
+			"Nothing is changed.",
+		Flags:  rangeFlags(false),
+		Action: status,
+	}
+}
+
+func status(c *cli.Context) error {
+	spin := newSpinner()
+	spin.Start()
+	defer spin.Stop()
+	_, months, r, err := loadMonths(c, spin)
+	if err != nil {
+		return err
+	}
+	spin.Stop()
+
+	st := factorial.RangeStatus(months, today)
+	if out.structured() {
+		return printStatusJSON(r, st)
+	}
+	for _, d := range st.Days {
+		fmt.Println(formatDayStatus(d))
+	}
+	fmt.Println()
+	fmt.Printf("Tracked:  %s\n", formatMinutes(st.Tracked))
+	fmt.Printf("Expected: %s\n", formatMinutes(st.Expected))
+	if st.ExpectedToDate != st.Expected {
+		fmt.Printf("Balance until today: %s\n", describeBalance(st.BalanceToDate))
+	}
+	fmt.Printf("Balance:  %s\n", describeBalance(st.Balance))
+	return nil
+}
+
+func formatDayStatus(d factorial.DayStatus) string {
+	message := fmt.Sprintf("%s... ", d.Date.Format("02 Jan"))
+	var segments []string
+	for _, s := range d.Shifts {
+		segments = append(segments, fmt.Sprintf("%s - %s", s.ClockIn, s.ClockOut))
+	}
+	times := fmt.Sprintf("%s / %s", formatMinutes(d.Tracked), formatMinutes(d.Expected))
+
+	switch d.State {
+	case factorial.DayComplete:
+		message += " ✅ " + times
+	case factorial.DayPartial:
+		message += fmt.Sprintf(" 🟡 %s (%s missing)", times, formatMinutes(d.Expected-d.Tracked))
+	case factorial.DayMissing:
+		message += " ❌ " + times
+	default:
+		switch {
+		case d.Leave != "":
+			message += " 🌴 " + d.Leave
+		case !d.Laborable && d.Date.Weekday() != time.Saturday && d.Date.Weekday() != time.Sunday:
+			message += " 🎉 Holiday"
+		case !d.Laborable:
+			message += " ➖ " + d.Date.Format("Monday")
+		default:
+			message += " ➖ Nothing expected"
+		}
+		if d.Tracked > 0 {
+			message += fmt.Sprintf(", %s tracked", formatMinutes(d.Tracked))
+		}
+	}
+	if len(segments) > 0 {
+		message += "  " + strings.Join(segments, ", ")
+	}
+	return message
+}
+
+func describeBalance(minutes int) string {
+	switch {
+	case minutes > 0:
+		return formatBalance(minutes) + " overtime"
+	case minutes < 0:
+		return formatBalance(minutes) + " deficit"
+	}
+	return formatBalance(minutes)
+}
+
+// statusRecord is the JSON output of a day's status. State is complete,
+// partial, missing or off.
+type statusRecord struct {
+	Type      string              `json:"type"`
+	Date      string              `json:"date"`
+	State     factorial.DayState  `json:"state"`
+	Laborable bool                `json:"laborable"`
+	Leave     string              `json:"leave,omitempty"`
+	Expected  int                 `json:"expected_minutes"`
+	Tracked   int                 `json:"tracked_minutes"`
+	Shifts    []factorial.Segment `json:"shifts"`
+}
+
+// statusSummary ends the JSON output of status. Balances are in minutes,
+// negative for a deficit.
+type statusSummary struct {
+	Type           string `json:"type"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Expected       int    `json:"expected_minutes"`
+	Tracked        int    `json:"tracked_minutes"`
+	Balance        int    `json:"balance_minutes"`
+	ExpectedToDate int    `json:"expected_minutes_to_date"`
+	TrackedToDate  int    `json:"tracked_minutes_to_date"`
+	BalanceToDate  int    `json:"balance_minutes_to_date"`
+}
+
+func printStatusJSON(r factorial.DateRange, st factorial.MonthStatus) error {
+	for _, d := range st.Days {
+		record := statusRecord{
+			Type:      "day",
+			Date:      d.Date.Format("2006-01-02"),
+			State:     d.State,
+			Laborable: d.Laborable,
+			Leave:     d.Leave,
+			Expected:  d.Expected,
+			Tracked:   d.Tracked,
+			Shifts:    []factorial.Segment{},
+		}
+		for _, s := range d.Shifts {
+			record.Shifts = append(record.Shifts, factorial.Segment{Start: s.ClockIn, End: s.ClockOut, Location: s.LocationType})
+		}
+		if err := out.record(record); err != nil {
+			return err
+		}
+	}
+	return out.summary(statusSummary{
+		Type:           "summary",
+		From:           r.From.Format("2006-01-02"),
+		To:             r.To.Format("2006-01-02"),
+		Expected:       st.Expected,
+		Tracked:        st.Tracked,
+		Balance:        st.Balance,
+		ExpectedToDate: st.ExpectedToDate,
+		TrackedToDate:  st.TrackedToDate,
+		BalanceToDate:  st.BalanceToDate,
+	})
+}
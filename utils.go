@@ -27,11 +27,56 @@ func readCredentials(c *cli.Context) (string, string) {
 		log.Fatalln("Email not valid")
 	}
 
-	fmt.Print("Password: ")
+	return email, readPassword()
+}
+
+func readPassword() string {
+	fmt.Fprint(out.prompts(), "Password: ")
 	bytePassword, _ := terminal.ReadPassword(int(syscall.Stdin))
+	fmt.Fprintln(out.prompts())
 	password := string(bytePassword)
 	if password == "" {
-		log.Fatalln("\nNo password provided")
+		log.Fatalln("No password provided")
+	}
+	return password
+}
+
+// confirm asks a yes or no question, anything but yes is a no
+func confirm(question string) bool {
+	fmt.Fprintf(out.prompts(), "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// splitList splits the values of a repeatable flag on commas, so both
+// --flag a --flag b and --flag a,b work
+func splitList(values []string) []string {
+	var list []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// formatMinutes formats minutes as hours and minutes, e.g. 8h 15m
+func formatMinutes(minutes int) string {
+	sign := ""
+	if minutes < 0 {
+		sign = "-"
+		minutes = -minutes
+	}
+	return fmt.Sprintf("%s%dh %02dm", sign, minutes/60, minutes%60)
+}
+
+// formatBalance formats a balance in minutes, always with its sign
+func formatBalance(minutes int) string {
+	if minutes >= 0 {
+		return "+" + formatMinutes(minutes)
 	}
-	return email, password
+	return formatMinutes(minutes)
 }